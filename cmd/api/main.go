@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,66 +11,152 @@ import (
 	"syscall"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/example/ppo/db"
+	"github.com/example/ppo/internal/auth"
 	"github.com/example/ppo/internal/config"
 	"github.com/example/ppo/internal/database"
 	"github.com/example/ppo/internal/server"
+	"github.com/example/ppo/pkg/envelope"
+	"github.com/example/ppo/pkg/redact"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	cfg, err := config.Load()
+	if err != nil {
+		newLogger(nil).Error("loading config", "error", err)
+		os.Exit(1)
+	}
+	logger := newLogger(cfg.LogRedactAllow)
+
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "import-settlement":
+		err = runImportSettlement(cfg, logger, os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "rotate-card-keys":
+		err = runRotateCardKeys(cfg, logger)
+	default:
+		err = run(cfg, logger)
+	}
 
-	if err := run(logger); err != nil {
+	if err != nil {
 		logger.Error("application failed", "error", err)
 		os.Exit(1)
 	}
 }
 
-func run(logger *slog.Logger) error {
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
-	}
+// newLogger returns the JSON logger every command uses. Sensitive attributes
+// (card tokens, emails, phone numbers, user IDs) are redacted unless their
+// key is in allow.
+func newLogger(allow []string) *slog.Logger {
+	return slog.New(redact.NewHandler(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		redact.Options{Allow: allow},
+	))
+}
 
+// openDatabase connects to Postgres and applies pending migrations. The
+// caller owns the returned *sql.DB and must close it.
+func openDatabase(cfg *config.Config) (*gorm.DB, *sql.DB, error) {
 	gormDB, err := database.New(cfg.DatabaseURL)
 	if err != nil {
-		return fmt.Errorf("connecting to database: %w", err)
+		return nil, nil, fmt.Errorf("connecting to database: %w", err)
 	}
 
 	sqlDB, err := gormDB.DB()
 	if err != nil {
-		return fmt.Errorf("getting sql.DB: %w", err)
+		return nil, nil, fmt.Errorf("getting sql.DB: %w", err)
 	}
-	defer sqlDB.Close()
 
 	if err := database.RunMigrations(sqlDB, db.Migrations); err != nil {
-		return fmt.Errorf("running migrations: %w", err)
+		sqlDB.Close()
+		return nil, nil, fmt.Errorf("running migrations: %w", err)
+	}
+
+	return gormDB, sqlDB, nil
+}
+
+// newSealer builds the card token sealer from the configured local keys.
+func newSealer(cfg *config.Config) (*envelope.Sealer, error) {
+	var (
+		current string
+		keys    map[string][]byte
+		err     error
+	)
+	switch {
+	case cfg.CardKeysFile != "":
+		current, keys, err = envelope.LoadKeyFile(cfg.CardKeysFile)
+	case cfg.CardKeys != "":
+		current = cfg.CardKeyID
+		keys, err = envelope.ParseKeys(cfg.CardKeys)
+	default:
+		return nil, fmt.Errorf("CARD_KEYS_FILE or CARD_KEYS must be set")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading card keys: %w", err)
 	}
 
-	srv := server.New(cfg, gormDB, logger)
+	provider, err := envelope.NewLocalKeyProvider(current, keys)
+	if err != nil {
+		return nil, fmt.Errorf("loading card keys: %w", err)
+	}
+	return envelope.New(provider), nil
+}
+
+// newVerifier builds the API token verifier, or returns nil when no keys are
+// configured.
+func newVerifier(cfg *config.Config) (*auth.Verifier, error) {
+	keys, err := auth.LoadKeys(cfg.AuthJWTSecret, cfg.AuthJWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading auth keys: %w", err)
+	}
+	if keys == nil {
+		return nil, nil
+	}
+	return auth.NewVerifier(keys, cfg.AuthIssuer, cfg.AuthAudience), nil
+}
+
+func run(cfg *config.Config, logger *slog.Logger) error {
+	gormDB, sqlDB, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	sealer, err := newSealer(cfg)
+	if err != nil {
+		return err
+	}
+
+	verifier, err := newVerifier(cfg)
+	if err != nil {
+		return err
+	}
+
+	srv, err := server.New(cfg, gormDB, sealer, verifier, logger)
+	if err != nil {
+		return err
+	}
 
 	if err := srv.Scheduler.Start(); err != nil {
 		return fmt.Errorf("starting scheduler: %w", err)
 	}
 	defer srv.Scheduler.Stop()
 
-	httpSrv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      srv.Router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	servers := []*http.Server{newHTTPServer(cfg.Port, srv.Router)}
+	if srv.AdminRouter != nil {
+		servers = append(servers, newHTTPServer(cfg.AdminPort, srv.AdminRouter))
 	}
 
-	errCh := make(chan error, 1)
-	go func() {
-		logger.Info("server starting", "port", cfg.Port)
-		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCh <- err
-		}
-	}()
+	errCh := make(chan error, len(servers))
+	for _, httpSrv := range servers {
+		go func() {
+			logger.Info("server starting", "addr", httpSrv.Addr)
+			if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- err
+			}
+		}()
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -84,10 +171,22 @@ func run(logger *slog.Logger) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := httpSrv.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server shutdown: %w", err)
+	for _, httpSrv := range servers {
+		if err := httpSrv.Shutdown(ctx); err != nil {
+			return fmt.Errorf("server shutdown: %w", err)
+		}
 	}
 
 	logger.Info("server stopped gracefully")
 	return nil
 }
+
+func newHTTPServer(port int, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/example/ppo/internal/config"
+	"github.com/example/ppo/internal/order"
+)
+
+// runRotateCardKeys implements `api rotate-card-keys`: it re-encrypts every
+// stored card token under the current key, including tokens still in
+// plaintext. Retire an old key only after this has run with it loaded.
+func runRotateCardKeys(cfg *config.Config, logger *slog.Logger) error {
+	sealer, err := newSealer(cfg)
+	if err != nil {
+		return err
+	}
+
+	gormDB, sqlDB, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	rotated, err := order.NewRepository(gormDB, sealer).RotateCardKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("rotating card keys after %d rows: %w", rotated, err)
+	}
+
+	logger.Info("card keys rotated", "rows", rotated)
+	return nil
+}
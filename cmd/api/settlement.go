@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/example/ppo/internal/config"
+	"github.com/example/ppo/internal/ledger"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/internal/settlement"
+)
+
+// runImportSettlement implements `api import-settlement <file.csv>`: it
+// imports a PSP settlement file and prints the match summary as JSON.
+func runImportSettlement(cfg *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: api import-settlement <file.csv>")
+	}
+	path := args[0]
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading settlement file: %w", err)
+	}
+
+	gormDB, sqlDB, err := openDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	ledgerSvc := ledger.NewService(ledger.NewRepository(gormDB), logger)
+	paymentSvc := payment.NewService(payment.NewRepository(gormDB), ledgerSvc, logger)
+	settlementSvc := settlement.NewService(settlement.NewRepository(gormDB), paymentSvc, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	f, err := settlementSvc.Import(ctx, filepath.Base(path), data)
+	if err != nil {
+		return fmt.Errorf("importing settlement file: %w", err)
+	}
+
+	summary := settlement.ToResponse(f)
+	summary.Rows = nil
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summary)
+}
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE payments (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type           VARCHAR(20) NOT NULL,
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    source         VARCHAR(32) NOT NULL,
+    amount         BIGINT NOT NULL,
+    currency       VARCHAR(3) NOT NULL,
+    psp_reference  VARCHAR(128) NOT NULL DEFAULT '',
+    loan_id        VARCHAR(64) NOT NULL DEFAULT '',
+    installment_id VARCHAR(64) NOT NULL DEFAULT '',
+    order_id       UUID REFERENCES orders(id),
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payments_loan_id ON payments(loan_id);
+CREATE INDEX idx_payments_installment_id ON payments(installment_id);
+CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE INDEX idx_payments_psp_reference ON payments(psp_reference);
+CREATE INDEX idx_payments_status_created_at ON payments(status, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE reconciliation_runs (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    window_from        TIMESTAMPTZ NOT NULL,
+    window_to          TIMESTAMPTZ NOT NULL,
+    status             VARCHAR(20) NOT NULL DEFAULT 'running',
+    checked_count      INT NOT NULL DEFAULT 0,
+    discrepancy_count  INT NOT NULL DEFAULT 0,
+    healed_count       INT NOT NULL DEFAULT 0,
+    error              TEXT NOT NULL DEFAULT '',
+    started_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at        TIMESTAMPTZ
+);
+
+CREATE INDEX idx_reconciliation_runs_started_at ON reconciliation_runs(started_at);
+
+CREATE TABLE reconciliation_discrepancies (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id          UUID NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    kind            VARCHAR(32) NOT NULL,
+    psp_reference   VARCHAR(128) NOT NULL DEFAULT '',
+    payment_type    VARCHAR(20) NOT NULL DEFAULT '',
+    loan_id         VARCHAR(64) NOT NULL DEFAULT '',
+    installment_id  VARCHAR(64) NOT NULL DEFAULT '',
+    local_amount    BIGINT NOT NULL DEFAULT 0,
+    psp_amount      BIGINT NOT NULL DEFAULT 0,
+    lms_amount      BIGINT NOT NULL DEFAULT 0,
+    healed          BOOLEAN NOT NULL DEFAULT FALSE,
+    detail          TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reconciliation_discrepancies_run_id ON reconciliation_discrepancies(run_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS reconciliation_discrepancies;
+DROP TABLE IF EXISTS reconciliation_runs;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE settlement_files (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    filename        VARCHAR(255) NOT NULL,
+    checksum        VARCHAR(64) NOT NULL UNIQUE,
+    row_count       INT NOT NULL DEFAULT 0,
+    matched_count   INT NOT NULL DEFAULT 0,
+    mismatch_count  INT NOT NULL DEFAULT 0,
+    unmatched_count INT NOT NULL DEFAULT 0,
+    imported_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE settlement_rows (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_id        UUID NOT NULL REFERENCES settlement_files(id) ON DELETE CASCADE,
+    line           INT NOT NULL,
+    transaction_id VARCHAR(128) NOT NULL,
+    amount         BIGINT NOT NULL,
+    currency       VARCHAR(3) NOT NULL,
+    fee            BIGINT NOT NULL DEFAULT 0,
+    status         VARCHAR(32) NOT NULL,
+    settled_date   DATE NOT NULL,
+    match_status   VARCHAR(20) NOT NULL,
+    payment_type   VARCHAR(20) NOT NULL DEFAULT '',
+    detail         TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_settlement_rows_file_id ON settlement_rows(file_id);
+CREATE INDEX idx_settlement_rows_transaction_id ON settlement_rows(transaction_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS settlement_rows;
+DROP TABLE IF EXISTS settlement_files;
@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE ledger_accounts (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code       VARCHAR(128) NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    currency   VARCHAR(3) NOT NULL,
+    user_id    VARCHAR(64) NOT NULL DEFAULT '',
+    loan_id    VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (code, currency)
+);
+
+CREATE INDEX idx_ledger_accounts_user_id ON ledger_accounts(user_id);
+CREATE INDEX idx_ledger_accounts_loan_id ON ledger_accounts(loan_id);
+
+CREATE TABLE ledger_entries (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind        VARCHAR(32) NOT NULL,
+    reference   VARCHAR(128) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    currency    VARCHAR(3) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, reference)
+);
+
+-- amount is signed: debits are positive, credits negative, so the postings
+-- of a balanced entry sum to zero.
+CREATE TABLE ledger_postings (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id   UUID NOT NULL REFERENCES ledger_entries(id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    amount     BIGINT NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account_id ON ledger_postings(account_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_accounts;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE psp_webhook_events (
+    nonce       VARCHAR(128) PRIMARY KEY,
+    event_id    VARCHAR(128) NOT NULL,
+    event_type  VARCHAR(20) NOT NULL,
+    reference   VARCHAR(128) NOT NULL,
+    status      VARCHAR(32) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_psp_webhook_events_reference ON psp_webhook_events(reference);
+
+-- +goose Down
+DROP TABLE IF EXISTS psp_webhook_events;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE payments ADD COLUMN action_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE payments DROP COLUMN IF EXISTS action_url;
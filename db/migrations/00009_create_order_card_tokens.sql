@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE order_card_tokens (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id        UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    card_token      VARCHAR(255) NOT NULL,
+    verification_id VARCHAR(128) NOT NULL DEFAULT '',
+    set_at          TIMESTAMPTZ NOT NULL,
+    replaced_at     TIMESTAMPTZ
+);
+
+CREATE INDEX idx_order_card_tokens_order_id ON order_card_tokens(order_id);
+CREATE UNIQUE INDEX idx_order_card_tokens_active ON order_card_tokens(order_id) WHERE replaced_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS order_card_tokens;
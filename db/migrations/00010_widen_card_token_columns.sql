@@ -0,0 +1,9 @@
+-- +goose Up
+-- card tokens are stored sealed (envelope encrypted), which no longer fits
+-- in 255 characters
+ALTER TABLE orders ALTER COLUMN card_token TYPE TEXT;
+ALTER TABLE order_card_tokens ALTER COLUMN card_token TYPE TEXT;
+
+-- +goose Down
+ALTER TABLE order_card_tokens ALTER COLUMN card_token TYPE VARCHAR(255);
+ALTER TABLE orders ALTER COLUMN card_token TYPE VARCHAR(255);
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE audit_events (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_type  VARCHAR(20) NOT NULL,
+    actor_id    VARCHAR(128) NOT NULL DEFAULT '',
+    action      VARCHAR(64) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id   VARCHAR(128) NOT NULL DEFAULT '',
+    before      JSONB,
+    after       JSONB,
+    request_id  VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, occurred_at);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_id, occurred_at);
+CREATE INDEX idx_audit_events_request_id ON audit_events(request_id);
+
+-- the trail is append-only: rows can be added but never changed or removed
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
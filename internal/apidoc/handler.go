@@ -0,0 +1,36 @@
+package apidoc
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+type Handler struct {
+	doc *Document
+}
+
+func NewHandler(doc *Document) *Handler {
+	return &Handler{doc: doc}
+}
+
+// RegisterRoutes serves the document and a Swagger UI page rendering it.
+// Both are public.
+func (h *Handler) RegisterRoutes(r gin.IRoutes) {
+	r.GET("/openapi.json", h.GetSpec)
+	r.GET("/docs", h.GetDocs)
+}
+
+// GetSpec returns the bare document, not the response envelope, so OpenAPI
+// tooling can read it directly.
+func (h *Handler) GetSpec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.doc)
+}
+
+func (h *Handler) GetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,60 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/postpurchase"
+)
+
+// PublicOperations lists every route the public API registers under
+// /api/v1. TestSpecMatchesRoutes fails when a RegisterRoutes and this list
+// disagree.
+var PublicOperations = []Operation{
+	{
+		Method: http.MethodPost, Path: "/orders", ID: "createOrder", Tag: "orders",
+		Summary: "Create an order", Status: http.StatusCreated,
+		Request: order.CreateRequest{}, Response: order.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/orders/:id/cancel", ID: "cancelOrder", Tag: "orders",
+		Summary:  "Cancel an order and refund what was paid",
+		Response: order.CancelResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/orders/:id/card", ID: "updateOrderCard", Tag: "orders",
+		Summary: "Replace the card used for future installments",
+		Request: order.UpdateCardRequest{}, Response: order.Response{},
+	},
+	{
+		Method: http.MethodGet, Path: "/orders/:id/cards", ID: "listOrderCards", Tag: "orders",
+		Summary:  "List the cards an order has had on file",
+		Response: []order.CardResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/users/:userId/installments", ID: "listInstallments", Tag: "installments",
+		Summary:  "List a user's installments",
+		Response: []lms.Installment{},
+	},
+	{
+		Method: http.MethodPost, Path: "/installments/pay", ID: "payInstallment", Tag: "installments",
+		Summary: "Pay one installment",
+		Request: postpurchase.PayInstallmentRequest{}, Response: postpurchase.PayInstallmentResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/installments/pay-batch", ID: "payInstallments", Tag: "installments",
+		Summary: "Pay several installments with one charge",
+		Request: postpurchase.PayInstallmentsRequest{}, Response: postpurchase.PayInstallmentsResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/loans/:loanId/payoff", ID: "payoffLoan", Tag: "loans",
+		Summary: "Settle a loan's remaining balance",
+		Request: postpurchase.PayoffRequest{}, Response: postpurchase.PayoffResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/payments/:paymentId/complete", ID: "completePayment", Tag: "payments",
+		Summary:  "Finish a payment after the 3-D Secure challenge",
+		Response: postpurchase.CompletePaymentResponse{},
+	},
+}
@@ -0,0 +1,202 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the API needs.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+var (
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas turns Go DTOs into component schemas. Request structs take
+// "required" and constraints from their gin binding tags; response structs
+// mark every field without omitempty as required, since it is always sent.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}}
+}
+
+// ref returns a schema for t, registering named structs as components.
+func (s *schemas) ref(t reflect.Type, request bool) *Schema {
+	switch t {
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := s.ref(t.Elem(), request)
+		if inner.Ref != "" {
+			return inner
+		}
+		inner.Nullable = true
+		return inner
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.ref(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.ref(t.Elem(), request)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, request)
+		}
+		name := componentName(t)
+		if _, ok := s.components[name]; !ok {
+			s.components[name] = &Schema{} // placeholder for recursive types
+			s.components[name] = s.object(t, request)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// object describes a struct's JSON fields. Embedded structs are flattened as
+// encoding/json does.
+func (s *schemas) object(t reflect.Type, request bool) *Schema {
+	obj := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := s.object(f.Type, request)
+			for k, v := range embedded.Properties {
+				obj.Properties[k] = v
+			}
+			obj.Required = append(obj.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := s.ref(f.Type, request)
+		binding := f.Tag.Get("binding")
+		if request {
+			applyBinding(prop, binding)
+		}
+		obj.Properties[name] = prop
+
+		required := !strings.Contains(opts, "omitempty")
+		if request {
+			required = hasRule(binding, "required")
+		}
+		if required {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+	return obj
+}
+
+// applyBinding maps the validator rules the DTOs use onto schema keywords.
+// Rules after "dive" apply to the items of a slice.
+func applyBinding(prop *Schema, binding string) {
+	target := prop
+	for _, rule := range strings.Split(binding, ",") {
+		key, val, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			if prop.Items != nil {
+				target = prop.Items
+			}
+		case "gt", "gte":
+			if n, err := strconv.ParseFloat(val, 64); err == nil {
+				target.Minimum = &n
+				target.ExclusiveMinimum = key == "gt"
+			}
+		case "lte":
+			if n, err := strconv.ParseFloat(val, 64); err == nil {
+				target.Maximum = &n
+			}
+		case "len":
+			if n, err := strconv.Atoi(val); err == nil {
+				target.MinLength, target.MaxLength = &n, &n
+			}
+		case "min":
+			if n, err := strconv.Atoi(val); err == nil {
+				if target.Type == "array" {
+					target.MinItems = &n
+				} else {
+					target.MinLength = &n
+				}
+			}
+		case "oneof":
+			target.Enum = strings.Fields(val)
+		case "uuid":
+			target.Format = "uuid"
+		case "datetime":
+			target.Format = "date-time"
+		}
+	}
+}
+
+func hasRule(binding, rule string) bool {
+	for _, r := range strings.Split(binding, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// componentName is "<package>.<Type>", e.g. "order.CreateRequest", which
+// keeps same-named DTOs of different packages apart.
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + t.Name()
+}
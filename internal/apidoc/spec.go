@@ -0,0 +1,208 @@
+// Package apidoc describes the public API as an OpenAPI 3 document. The
+// document is generated from the handler DTOs, so request schemas follow the
+// same binding tags gin validates against.
+package apidoc
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/example/ppo/pkg/response"
+)
+
+// Operation declares one endpoint. Path uses gin syntax (":id"). Request
+// and Response are zero values of the DTOs; Response is the "data" of a
+// successful envelope and may be nil for endpoints that return no data.
+type Operation struct {
+	Method   string
+	Path     string
+	ID       string
+	Summary  string
+	Tag      string
+	Status   int
+	Request  any
+	Response any
+}
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	Responses       map[string]*Response      `json:"responses"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// errorResponses are the failures any endpoint may answer with, all in the
+// standard error envelope.
+var errorResponses = map[string]string{
+	"400": "Invalid request",
+	"401": "Missing or invalid bearer token",
+	"402": "Card declined by the issuer",
+	"403": "Resource belongs to another user",
+	"404": "Not found",
+	"409": "Conflict with the current state",
+	"429": "Rate limited; see Retry-After",
+	"502": "Upstream service failed",
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// OpenAPIPath converts a gin route pattern to OpenAPI syntax.
+func OpenAPIPath(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Build assembles the document for ops, which are mounted under basePath.
+func Build(info Info, basePath string, ops []Operation) *Document {
+	s := newSchemas()
+
+	errorEnvelope := s.ref(reflect.TypeOf(response.APIResponse{}), false)
+	s.ref(reflect.TypeOf(response.ErrorBody{}), false)
+	problem := s.ref(reflect.TypeOf(response.ProblemDetails{}), false)
+
+	doc := &Document{
+		OpenAPI:  "3.0.3",
+		Info:     info,
+		Servers:  []Server{{URL: basePath}},
+		Security: []map[string][]string{{"bearerAuth": {}}},
+		Paths:    map[string]PathItem{},
+		Components: Components{
+			Responses: map[string]*Response{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	for code, desc := range errorResponses {
+		doc.Components.Responses["Error"+code] = &Response{
+			Description: desc,
+			Content: map[string]MediaType{
+				"application/json":          {Schema: errorEnvelope},
+				response.ProblemContentType: {Schema: problem},
+			},
+		}
+	}
+
+	for _, op := range ops {
+		path := OpenAPIPath(op.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = buildOperation(s, op)
+	}
+
+	doc.Components.Schemas = s.components
+	return doc
+}
+
+func buildOperation(s *schemas, op Operation) *OperationObject {
+	o := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Tags:        []string{op.Tag},
+		Responses:   map[string]*Response{},
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if op.Request != nil {
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: s.ref(reflect.TypeOf(op.Request), true)},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	envelope := &Schema{
+		Type:       "object",
+		Required:   []string{"success"},
+		Properties: map[string]*Schema{"success": {Type: "boolean"}},
+	}
+	if op.Response != nil {
+		envelope.Properties["data"] = s.ref(reflect.TypeOf(op.Response), false)
+		envelope.Required = append(envelope.Required, "data")
+	}
+	o.Responses[strconv.Itoa(status)] = &Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{"application/json": {Schema: envelope}},
+	}
+
+	for code := range errorResponses {
+		o.Responses[code] = &Response{Ref: "#/components/responses/Error" + code}
+	}
+	return o
+}
@@ -0,0 +1,119 @@
+package apidoc
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/postpurchase"
+)
+
+func testDocument() *Document {
+	return Build(Info{Title: "test", Version: "1"}, "/api/v1", PublicOperations)
+}
+
+// TestSpecMatchesRoutes fails when a route is added to or removed from the
+// public API without updating PublicOperations, or the other way round.
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	order.NewHandler(nil).RegisterRoutes(v1)
+	postpurchase.NewHandler(nil).RegisterRoutes(v1)
+
+	var routes []string
+	for _, ri := range r.Routes() {
+		routes = append(routes, ri.Method+" "+OpenAPIPath(strings.TrimPrefix(ri.Path, "/api/v1")))
+	}
+
+	var documented []string
+	for path, item := range testDocument().Paths {
+		for method := range item {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	for _, route := range routes {
+		if !slices.Contains(documented, route) {
+			t.Errorf("route %s is registered but missing from PublicOperations", route)
+		}
+	}
+	for _, op := range documented {
+		if !slices.Contains(routes, op) {
+			t.Errorf("operation %s is documented but not registered", op)
+		}
+	}
+}
+
+func TestBuild_RequestSchemaFollowsBindingTags(t *testing.T) {
+	doc := testDocument()
+
+	req := doc.Components.Schemas["postpurchase.PayInstallmentsRequest"]
+	if req == nil {
+		t.Fatal("expected PayInstallmentsRequest component")
+	}
+	if !slices.Equal(sorted(req.Required), []string{"card_token", "currency", "installments"}) {
+		t.Errorf("unexpected required fields %v", req.Required)
+	}
+	if cur := req.Properties["currency"]; *cur.MinLength != 3 || *cur.MaxLength != 3 {
+		t.Errorf("expected currency length 3, got %+v", cur)
+	}
+	if items := req.Properties["installments"]; items.Type != "array" || *items.MinItems != 1 {
+		t.Errorf("expected non-empty installments array, got %+v", items)
+	}
+
+	input := doc.Components.Schemas["postpurchase.InstallmentPaymentInput"]
+	if amount := input.Properties["amount"]; amount.Format != "int64" || *amount.Minimum != 0 || !amount.ExclusiveMinimum {
+		t.Errorf("expected positive int64 amount, got %+v", amount)
+	}
+
+	create := doc.Components.Schemas["order.CreateRequest"]
+	if uid := create.Properties["user_id"]; uid.Format != "uuid" {
+		t.Errorf("expected uuid user_id, got %+v", uid)
+	}
+}
+
+func TestBuild_ResponseEnvelopeAndParams(t *testing.T) {
+	doc := testDocument()
+
+	op := doc.Paths["/orders/{id}/cancel"]["post"]
+	if op == nil {
+		t.Fatal("expected cancel operation")
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+		t.Errorf("unexpected parameters %+v", op.Parameters)
+	}
+	if op.Responses["429"].Ref != "#/components/responses/Error429" {
+		t.Errorf("expected shared 429 response, got %+v", op.Responses["429"])
+	}
+
+	created := doc.Paths["/orders"]["post"].Responses["201"]
+	data := created.Content["application/json"].Schema.Properties["data"]
+	if data.Ref != "#/components/schemas/order.Response" {
+		t.Errorf("expected order.Response data, got %+v", data)
+	}
+
+	// response fields without omitempty are always present
+	resp := doc.Components.Schemas["postpurchase.PayInstallmentResponse"]
+	if slices.Contains(resp.Required, "redirect_url") || !slices.Contains(resp.Required, "payment_id") {
+		t.Errorf("unexpected required response fields %v", resp.Required)
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("document does not encode: %v", err)
+	}
+	if http.StatusText(http.StatusCreated) != created.Description {
+		t.Errorf("unexpected description %q", created.Description)
+	}
+}
+
+func sorted(s []string) []string {
+	out := slices.Clone(s)
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListQuery is bound from the query string of GET /audit-events. From and To
+// are RFC 3339 timestamps.
+type ListQuery struct {
+	ActorType  ActorType `form:"actor_type" binding:"omitempty,oneof=customer operator system"`
+	ActorID    string    `form:"actor_id"`
+	Action     string    `form:"action"`
+	EntityType string    `form:"entity_type"`
+	EntityID   string    `form:"entity_id"`
+	RequestID  string    `form:"request_id"`
+	From       string    `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string    `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit      int       `form:"limit" binding:"omitempty,gt=0,lte=500"`
+	Offset     int       `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// defaultLimit caps a listing when the query does not.
+const defaultLimit = 100
+
+// ToFilter converts the already-validated query into a repository filter.
+func (q ListQuery) ToFilter() Filter {
+	f := Filter{
+		ActorType:  q.ActorType,
+		ActorID:    q.ActorID,
+		Action:     q.Action,
+		EntityType: q.EntityType,
+		EntityID:   q.EntityID,
+		RequestID:  q.RequestID,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultLimit
+	}
+	f.From, _ = time.Parse(time.RFC3339, q.From)
+	f.To, _ = time.Parse(time.RFC3339, q.To)
+	return f
+}
+
+type Response struct {
+	ID         uuid.UUID       `json:"id"`
+	OccurredAt string          `json:"occurred_at"`
+	ActorType  ActorType       `json:"actor_type"`
+	ActorID    string          `json:"actor_id,omitempty"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+}
+
+func ToResponse(e *Event) Response {
+	return Response{
+		ID:         e.ID,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339),
+		ActorType:  e.ActorType,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     e.Before,
+		After:      e.After,
+		RequestID:  e.RequestID,
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/audit-events", h.ListEvents)
+}
+
+func (h *Handler) ListEvents(c *gin.Context) {
+	var q ListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	events, err := h.svc.List(c.Request.Context(), q.ToFilter())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]Response, len(events))
+	for i := range events {
+		out[i] = ToResponse(&events[i])
+	}
+	response.OK(c, out)
+}
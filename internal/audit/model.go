@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActorType says who performed an audited action.
+type ActorType string
+
+const (
+	ActorCustomer ActorType = "customer"
+	ActorOperator ActorType = "operator"
+	// ActorSystem covers scheduled jobs and PSP webhooks.
+	ActorSystem ActorType = "system"
+)
+
+// Actions recorded in the trail, named "<entity>.<verb>".
+const (
+	ActionOrderCreate      = "order.create"
+	ActionOrderCancel      = "order.cancel"
+	ActionOrderForceCancel = "order.force_cancel"
+	ActionOrderRefunded    = "order.refunded"
+	ActionOrderCardUpdate  = "order.card_update"
+	ActionPaymentPay       = "payment.pay"
+	ActionPaymentPayoff    = "payment.payoff"
+	ActionPaymentComplete  = "payment.complete"
+	ActionAdminRequest     = "admin.request"
+)
+
+// Entity types the trail refers to.
+const (
+	EntityOrder   = "order"
+	EntityPayment = "payment"
+	EntityRoute   = "route"
+)
+
+// Event is one row of the append-only audit trail. Before and After are JSON
+// snapshots of the entity around the action; either may be empty.
+type Event struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OccurredAt time.Time       `gorm:"not null"`
+	ActorType  ActorType       `gorm:"type:varchar(20);not null"`
+	ActorID    string          `gorm:"type:varchar(128);not null;default:''"`
+	Action     string          `gorm:"type:varchar(64);not null"`
+	EntityType string          `gorm:"type:varchar(32);not null"`
+	EntityID   string          `gorm:"type:varchar(128);not null;default:''"`
+	Before     json.RawMessage `gorm:"type:jsonb"`
+	After      json.RawMessage `gorm:"type:jsonb"`
+	RequestID  string          `gorm:"type:varchar(64);not null;default:''"`
+}
+
+func (Event) TableName() string { return "audit_events" }
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Filter narrows an audit listing. Zero values are ignored.
+type Filter struct {
+	ActorType  ActorType
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+// Repository only appends and reads; the table rejects updates and deletes.
+type Repository interface {
+	Create(ctx context.Context, e *Event) error
+	List(ctx context.Context, f Filter) ([]Event, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, e *Event) error {
+	if err := r.db.WithContext(ctx).Create(e).Error; err != nil {
+		return apperror.NewInternal("creating audit event", err)
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, f Filter) ([]Event, error) {
+	q := r.db.WithContext(ctx).Model(&Event{})
+	if f.ActorType != "" {
+		q = q.Where("actor_type = ?", f.ActorType)
+	}
+	if f.ActorID != "" {
+		q = q.Where("actor_id = ?", f.ActorID)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.EntityType != "" {
+		q = q.Where("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID != "" {
+		q = q.Where("entity_id = ?", f.EntityID)
+	}
+	if f.RequestID != "" {
+		q = q.Where("request_id = ?", f.RequestID)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("occurred_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("occurred_at < ?", f.To)
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+
+	var events []Event
+	if err := q.Order("occurred_at DESC").Find(&events).Error; err != nil {
+		return nil, apperror.NewInternal("listing audit events", err)
+	}
+	return events, nil
+}
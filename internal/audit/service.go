@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/internal/middleware"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Entry describes an action to record. The actor and request ID are taken
+// from ctx. Before and After are marshalled to JSON; pass response DTOs
+// rather than models so no secret ends up in the trail.
+type Entry struct {
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     any
+	After      any
+}
+
+type Service interface {
+	// Record appends an entry to the trail. It is called after the action
+	// has happened, so callers only log its error.
+	Record(ctx context.Context, e Entry) error
+	List(ctx context.Context, f Filter) ([]Event, error)
+}
+
+type service struct {
+	repo   Repository
+	logger *slog.Logger
+}
+
+func NewService(repo Repository, logger *slog.Logger) Service {
+	return &service{repo: repo, logger: logger}
+}
+
+func (s *service) Record(ctx context.Context, e Entry) error {
+	log := s.logger.With("action", e.Action, "entity_type", e.EntityType, "entity_id", e.EntityID)
+
+	before, err := snapshot(e.Before)
+	if err != nil {
+		log.Error("failed to encode audit snapshot", "error", err)
+		return apperror.NewInternal("encoding audit snapshot", err)
+	}
+	after, err := snapshot(e.After)
+	if err != nil {
+		log.Error("failed to encode audit snapshot", "error", err)
+		return apperror.NewInternal("encoding audit snapshot", err)
+	}
+
+	actorType, actorID := actor(ctx)
+	ev := &Event{
+		OccurredAt: time.Now().UTC(),
+		ActorType:  actorType,
+		ActorID:    actorID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     before,
+		After:      after,
+		RequestID:  middleware.RequestIDFrom(ctx),
+	}
+	if err := s.repo.Create(ctx, ev); err != nil {
+		log.Error("failed to record audit event", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) List(ctx context.Context, f Filter) ([]Event, error) {
+	return s.repo.List(ctx, f)
+}
+
+// actor identifies who is behind ctx: an operator on the admin API, a
+// customer on the public API, or the system otherwise.
+func actor(ctx context.Context) (ActorType, string) {
+	if op, ok := auth.OperatorFrom(ctx); ok {
+		return ActorOperator, op.Subject
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		return ActorCustomer, p.UserID.String()
+	}
+	return ActorSystem, ""
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
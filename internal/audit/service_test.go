@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/internal/middleware"
+)
+
+type memRepo struct {
+	events []Event
+}
+
+func (r *memRepo) Create(_ context.Context, e *Event) error {
+	r.events = append(r.events, *e)
+	return nil
+}
+
+func (r *memRepo) List(context.Context, Filter) ([]Event, error) { return r.events, nil }
+
+func newTestService() (Service, *memRepo) {
+	repo := &memRepo{}
+	return NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))), repo
+}
+
+func TestRecord_Actor(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantType  ActorType
+		wantActor string
+	}{
+		{name: "system", ctx: context.Background(), wantType: ActorSystem},
+		{
+			name:      "customer",
+			ctx:       auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID}),
+			wantType:  ActorCustomer,
+			wantActor: userID.String(),
+		},
+		{
+			name:      "operator",
+			ctx:       auth.WithOperator(context.Background(), &auth.Operator{Subject: "ops-jane"}),
+			wantType:  ActorOperator,
+			wantActor: "ops-jane",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService()
+			if err := svc.Record(tt.ctx, Entry{Action: ActionOrderCancel, EntityType: EntityOrder, EntityID: "o-1"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ev := repo.events[0]
+			if ev.ActorType != tt.wantType || ev.ActorID != tt.wantActor {
+				t.Errorf("expected actor %s/%q, got %s/%q", tt.wantType, tt.wantActor, ev.ActorType, ev.ActorID)
+			}
+			if ev.OccurredAt.IsZero() {
+				t.Error("expected OccurredAt to be set")
+			}
+		})
+	}
+}
+
+func TestRecord_SnapshotsAndRequestID(t *testing.T) {
+	svc, repo := newTestService()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.POST("/orders/:id/cancel", func(c *gin.Context) {
+		_ = svc.Record(c.Request.Context(), Entry{
+			Action:     ActionOrderCancel,
+			EntityType: EntityOrder,
+			EntityID:   c.Param("id"),
+			Before:     map[string]string{"status": "active"},
+			After:      map[string]string{"status": "refunded"},
+		})
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders/o-1/cancel", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	ev := repo.events[0]
+	if ev.RequestID != "req-123" {
+		t.Errorf("expected request id req-123, got %q", ev.RequestID)
+	}
+	if w.Header().Get(middleware.RequestIDHeader) != "req-123" {
+		t.Errorf("expected request id echoed in response, got %q", w.Header().Get(middleware.RequestIDHeader))
+	}
+	if string(ev.Before) != `{"status":"active"}` || string(ev.After) != `{"status":"refunded"}` {
+		t.Errorf("unexpected snapshots: before=%s after=%s", ev.Before, ev.After)
+	}
+}
+
+func TestRecord_NoSnapshot(t *testing.T) {
+	svc, repo := newTestService()
+
+	_ = svc.Record(context.Background(), Entry{Action: ActionOrderCreate, EntityType: EntityOrder})
+
+	if repo.events[0].Before != nil {
+		t.Errorf("expected no before snapshot, got %s", repo.events[0].Before)
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Keys holds the keys tokens may be signed with: an HS256 shared secret for
+// development, RS256 public keys by key ID from a JWKS file, or both.
+type Keys struct {
+	secret []byte
+	rsa    map[string]*rsa.PublicKey
+}
+
+// NewStaticKey accepts HS256 tokens signed with secret.
+func NewStaticKey(secret string) *Keys {
+	return &Keys{secret: []byte(secret)}
+}
+
+// LoadKeys combines the configured key sources. It returns nil keys when
+// neither a secret nor a JWKS file is configured.
+func LoadKeys(secret, jwksPath string) (*Keys, error) {
+	if secret == "" && jwksPath == "" {
+		return nil, nil
+	}
+
+	keys := NewStaticKey(secret)
+	if jwksPath != "" {
+		loaded, err := LoadJWKS(jwksPath)
+		if err != nil {
+			return nil, err
+		}
+		keys.rsa = loaded.rsa
+	}
+	return keys, nil
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// LoadJWKS accepts RS256 tokens signed by any RSA key in the JWKS file at
+// path. Tokens must name their key in the "kid" header.
+func LoadJWKS(path string) (*Keys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS file: %w", err)
+	}
+
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decoding JWKS file: %w", err)
+	}
+
+	keys := &Keys{rsa: map[string]*rsa.PublicKey{}}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: invalid exponent: %w", k.Kid, err)
+		}
+		keys.rsa[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys.rsa) == 0 {
+		return nil, errors.New("JWKS file has no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	Claims *Claims
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the customer calling the public API, if the request
+// was authenticated as one.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// RequireUser fails with a forbidden error unless the authenticated customer
+// is userID. Work that runs without a customer — scheduled jobs, webhooks,
+// operators on the admin API — is not restricted.
+func RequireUser(ctx context.Context, userID uuid.UUID) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if p.UserID != userID {
+		return apperror.NewForbidden("resource belongs to another user").Localized("auth.not_owner")
+	}
+	return nil
+}
+
+// Authenticate rejects requests without a valid bearer token and stores the
+// caller in the request context. A nil verifier rejects every request, so a
+// missing auth configuration fails closed.
+func Authenticate(v *Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, err := authenticate(v, c.GetHeader("Authorization"))
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+func authenticate(v *Verifier, header string) (*Principal, error) {
+	claims, err := verifyBearer(v, header)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, apperror.NewUnauthorized("token subject is not a user id").Localized("auth.subject_not_user")
+	}
+	return &Principal{UserID: userID, Claims: claims}, nil
+}
+
+func verifyBearer(v *Verifier, header string) (*Claims, error) {
+	if v == nil {
+		return nil, apperror.NewUnauthorized("authentication is not configured").Localized("auth.not_configured")
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, apperror.NewUnauthorized("missing bearer token").Localized("auth.missing_token")
+	}
+
+	claims, err := v.Verify(strings.TrimSpace(token))
+	if errors.Is(err, ErrExpired) {
+		return nil, apperror.NewUnauthorized("token expired").Localized("auth.token_expired")
+	}
+	if err != nil {
+		return nil, apperror.NewUnauthorized("invalid token").Localized("auth.invalid_token")
+	}
+	return claims, nil
+}
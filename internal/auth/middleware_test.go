@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/middleware"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+func newTestRouter(v *Verifier) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/users/:userId", Authenticate(v), func(c *gin.Context) {
+		id, _ := uuid.Parse(c.Param("userId"))
+		if err := RequireUser(c.Request.Context(), id); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	v := newTestVerifier(NewStaticKey("dev-secret"))
+	token := signHS256("dev-secret", map[string]any{"alg": "HS256"}, validClaims())
+
+	tests := []struct {
+		name       string
+		verifier   *Verifier
+		header     string
+		path       string
+		wantStatus int
+	}{
+		{name: "own resource", verifier: v, header: "Bearer " + token, path: "/users/" + testUser, wantStatus: http.StatusNoContent},
+		{name: "other user", verifier: v, header: "Bearer " + token, path: "/users/" + uuid.NewString(), wantStatus: http.StatusForbidden},
+		{name: "missing header", verifier: v, path: "/users/" + testUser, wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", verifier: v, header: "Basic " + token, path: "/users/" + testUser, wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", verifier: v, header: "Bearer " + token + "x", path: "/users/" + testUser, wantStatus: http.StatusUnauthorized},
+		{name: "not configured", verifier: nil, header: "Bearer " + token, path: "/users/" + testUser, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			newTestRouter(tt.verifier).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireUser_NoPrincipal(t *testing.T) {
+	if err := RequireUser(context.Background(), uuid.New()); err != nil {
+		t.Errorf("expected calls without a principal to pass, got %v", err)
+	}
+
+	ctx := WithPrincipal(context.Background(), &Principal{UserID: uuid.New()})
+	err := RequireUser(ctx, uuid.New())
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Kind != apperror.KindForbidden {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Permissions are "<resource>:<access>", where access is read or write.
+// "<resource>:*" and "*" grant every access to one or all resources.
+const (
+	accessRead  = "read"
+	accessWrite = "write"
+)
+
+// rolePermissions maps the roles operators carry in their token to the
+// permissions they grant. Tokens may also grant permissions directly.
+var rolePermissions = map[string][]string{
+	"admin":   {"*"},
+	"ops":     {"orders:*", "jobs:*", "payments:read", "reconciliations:*", "settlements:read", "ledger:read", "audit:read"},
+	"finance": {"payments:read", "reconciliations:*", "settlements:*", "ledger:read", "audit:read"},
+	"support": {"orders:read", "payments:read"},
+}
+
+// Operator is a member of staff calling the admin API. Subject is whatever
+// the identity provider uses for staff and need not be a customer ID.
+type Operator struct {
+	Subject     string
+	Roles       []string
+	permissions []string
+}
+
+func newOperator(c *Claims) *Operator {
+	op := &Operator{Subject: c.Subject, Roles: c.Roles, permissions: slices.Clone(c.Permissions)}
+	for _, r := range c.Roles {
+		op.permissions = append(op.permissions, rolePermissions[r]...)
+	}
+	return op
+}
+
+// Can reports whether the operator holds permission, directly or through a
+// wildcard.
+func (o *Operator) Can(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, p := range o.permissions {
+		if p == "*" || p == permission || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+type operatorKey struct{}
+
+func WithOperator(ctx context.Context, op *Operator) context.Context {
+	return context.WithValue(ctx, operatorKey{}, op)
+}
+
+// OperatorFrom returns the operator calling the admin API, if any.
+func OperatorFrom(ctx context.Context) (*Operator, bool) {
+	op, ok := ctx.Value(operatorKey{}).(*Operator)
+	return op, ok
+}
+
+// AuthenticateOperator is Authenticate for the admin API: it accepts any
+// token subject and records the caller as an Operator. What the operator may
+// do is decided per route by RequireAccess.
+func AuthenticateOperator(v *Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifyBearer(v, c.GetHeader("Authorization"))
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(WithOperator(c.Request.Context(), newOperator(claims)))
+		c.Next()
+	}
+}
+
+// RequireAccess allows the request only if the operator may access resource:
+// "<resource>:read" for GET and HEAD, "<resource>:write" for anything else.
+func RequireAccess(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access := accessWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			access = accessRead
+		}
+		permission := resource + ":" + access
+
+		op, ok := OperatorFrom(c.Request.Context())
+		if !ok {
+			_ = c.Error(apperror.NewUnauthorized("missing bearer token").Localized("auth.missing_token"))
+			c.Abort()
+			return
+		}
+		if !op.Can(permission) {
+			_ = c.Error(apperror.NewForbidden(fmt.Sprintf("permission %s required", permission)).
+				Localized("auth.permission_required", "permission", permission))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/internal/middleware"
+)
+
+func TestOperatorCan(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     Claims
+		permission string
+		want       bool
+	}{
+		{name: "admin wildcard", claims: Claims{Roles: []string{"admin"}}, permission: "settlements:write", want: true},
+		{name: "resource wildcard", claims: Claims{Roles: []string{"ops"}}, permission: "orders:write", want: true},
+		{name: "read only role", claims: Claims{Roles: []string{"support"}}, permission: "orders:write", want: false},
+		{name: "direct permission", claims: Claims{Permissions: []string{"ledger:read"}}, permission: "ledger:read", want: true},
+		{name: "unknown role", claims: Claims{Roles: []string{"customer"}}, permission: "orders:read", want: false},
+		{name: "no claims", permission: "payments:read", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newOperator(&tt.claims).Can(tt.permission); got != tt.want {
+				t.Errorf("Can(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireAccess(t *testing.T) {
+	v := newTestVerifier(NewStaticKey("dev-secret"))
+	tokenWith := func(roles ...string) string {
+		c := validClaims()
+		c["sub"] = "ops-jane"
+		c["roles"] = roles
+		return signHS256("dev-secret", map[string]any{"alg": "HS256"}, c)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	orders := r.Group("/admin/orders", AuthenticateOperator(v), RequireAccess("orders"))
+	orders.GET("", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	orders.POST("", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+	}{
+		{name: "support reads", method: http.MethodGet, token: tokenWith("support"), wantStatus: http.StatusNoContent},
+		{name: "support writes", method: http.MethodPost, token: tokenWith("support"), wantStatus: http.StatusForbidden},
+		{name: "ops writes", method: http.MethodPost, token: tokenWith("ops"), wantStatus: http.StatusNoContent},
+		{name: "customer", method: http.MethodGet, token: tokenWith(), wantStatus: http.StatusForbidden},
+		{name: "anonymous", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/orders", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const testUser = "3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11"
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": testUser,
+		"iss": "https://id.example.com",
+		"aud": "ppo-api",
+		"exp": testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func encodeSegment(v any) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(secret string, hdr, claims map[string]any) string {
+	signed := encodeSegment(hdr) + "." + encodeSegment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	signed := encodeSegment(map[string]any{"alg": "RS256", "kid": kid}) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func newTestVerifier(keys *Keys) *Verifier {
+	v := NewVerifier(keys, "https://id.example.com", "ppo-api")
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func TestVerify_HS256(t *testing.T) {
+	v := newTestVerifier(NewStaticKey("dev-secret"))
+	hs := map[string]any{"alg": "HS256", "typ": "JWT"}
+
+	with := func(k string, val any) map[string]any {
+		c := validClaims()
+		if val == nil {
+			delete(c, k)
+		} else {
+			c[k] = val
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: signHS256("dev-secret", hs, validClaims())},
+		{name: "audience array", token: signHS256("dev-secret", hs, with("aud", []string{"other", "ppo-api"}))},
+		{name: "wrong secret", token: signHS256("other-secret", hs, validClaims()), wantErr: ErrInvalidSignature},
+		{name: "alg none", token: encodeSegment(map[string]any{"alg": "none"}) + "." + encodeSegment(validClaims()) + ".", wantErr: ErrInvalidSignature},
+		{name: "expired", token: signHS256("dev-secret", hs, with("exp", testNow.Add(-time.Hour).Unix())), wantErr: ErrExpired},
+		{name: "missing exp", token: signHS256("dev-secret", hs, with("exp", nil)), wantErr: ErrInvalidClaims},
+		{name: "not yet valid", token: signHS256("dev-secret", hs, with("nbf", testNow.Add(time.Hour).Unix())), wantErr: ErrInvalidClaims},
+		{name: "wrong issuer", token: signHS256("dev-secret", hs, with("iss", "https://evil.example.com")), wantErr: ErrInvalidClaims},
+		{name: "wrong audience", token: signHS256("dev-secret", hs, with("aud", "other")), wantErr: ErrInvalidClaims},
+		{name: "missing subject", token: signHS256("dev-secret", hs, with("sub", nil)), wantErr: ErrInvalidClaims},
+		{name: "malformed", token: "not-a-token", wantErr: ErrMalformedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && claims.Subject != testUser {
+				t.Errorf("expected subject %s, got %s", testUser, claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerify_RS256FromJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	jwks := map[string]any{"keys": []map[string]any{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, _ := json.Marshal(jwks)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeys("", path)
+	if err != nil {
+		t.Fatalf("loading JWKS: %v", err)
+	}
+	v := newTestVerifier(keys)
+
+	if _, err := v.Verify(signRS256(key, "key-1", validClaims())); err != nil {
+		t.Errorf("expected valid token, got %v", err)
+	}
+	if _, err := v.Verify(signRS256(other, "key-1", validClaims())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for foreign key, got %v", err)
+	}
+	if _, err := v.Verify(signRS256(key, "key-2", validClaims())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for unknown kid, got %v", err)
+	}
+	// without a static secret configured, HS256 tokens are refused outright
+	if _, err := v.Verify(signHS256("", map[string]any{"alg": "HS256"}, validClaims())); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for HS256, got %v", err)
+	}
+}
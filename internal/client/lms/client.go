@@ -1,12 +1,22 @@
 package lms
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned (wrapped) when LMS answers 404 for a loan or
+// installment lookup.
+var ErrNotFound = errors.New("not found in LMS")
 
 type Client interface {
 	GetLoan(ctx context.Context, loanID string) (*Loan, error)
+	GetInstallment(ctx context.Context, installmentID string) (*Installment, error)
 	GetInstallments(ctx context.Context, userID string) ([]Installment, error)
-	GetUpcomingInstallments(ctx context.Context) ([]Installment, error)
-	GetOverdueInstallments(ctx context.Context) ([]Installment, error)
+	ListInstallments(ctx context.Context, req ListInstallmentsRequest) (*InstallmentPage, error)
 	UpdateLoanStatus(ctx context.Context, loanID, status string) error
 	RecordPayment(ctx context.Context, req RecordPaymentRequest) error
+	GetPayments(ctx context.Context, transactionID string) ([]Payment, error)
+	ReversePayment(ctx context.Context, req ReversePaymentRequest) error
+	SettleLoan(ctx context.Context, req SettleLoanRequest) error
 }
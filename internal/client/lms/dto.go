@@ -1,19 +1,51 @@
 package lms
 
+const (
+	LoanStatusActive   = "active"
+	LoanStatusRefunded = "refunded"
+)
+
+const (
+	InstallmentStatusPaid     = "paid"
+	InstallmentStatusUpcoming = "upcoming"
+	InstallmentStatusOverdue  = "overdue"
+)
+
 type Loan struct {
 	ID          string `json:"id"`
 	UserID      string `json:"user_id"`
 	Status      string `json:"status"`
 	PaidAmount  int64  `json:"paid_amount"`
 	TotalAmount int64  `json:"total_amount"`
+	// EarlyPayoffDiscount is the amount LMS waives if the whole outstanding
+	// balance is settled today.
+	EarlyPayoffDiscount int64 `json:"early_payoff_discount"`
 }
 
+// Outstanding is the amount the customer still owes on the loan.
+func (l *Loan) Outstanding() int64 { return l.TotalAmount - l.PaidAmount }
+
 type Installment struct {
-	ID      string `json:"id"`
-	LoanID  string `json:"loan_id"`
-	Amount  int64  `json:"amount"`
-	Status  string `json:"status"`
-	DueDate string `json:"due_date"`
+	ID       string `json:"id"`
+	LoanID   string `json:"loan_id"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Status   string `json:"status"`
+	DueDate  string `json:"due_date"`
+}
+
+// ListInstallmentsRequest filters a paginated installment listing. Cursor is
+// the opaque NextCursor of the previous page; leave it empty for the first.
+type ListInstallmentsRequest struct {
+	Status string
+	UserID string
+	Cursor string
+	Limit  int
+}
+
+type InstallmentPage struct {
+	Items      []Installment `json:"items"`
+	NextCursor string        `json:"next_cursor"`
 }
 
 type RecordPaymentRequest struct {
@@ -22,3 +54,29 @@ type RecordPaymentRequest struct {
 	Amount        int64  `json:"amount"`
 	TransactionID string `json:"transaction_id"`
 }
+
+// Payment is LMS's record of money applied to a loan. InstallmentID is empty
+// for loan settlements.
+type Payment struct {
+	LoanID        string `json:"loan_id"`
+	InstallmentID string `json:"installment_id"`
+	Amount        int64  `json:"amount"`
+	TransactionID string `json:"transaction_id"`
+}
+
+// ReversePaymentRequest undoes a previously recorded payment, used to roll
+// back a partially applied multi-installment allocation.
+type ReversePaymentRequest struct {
+	LoanID        string `json:"loan_id"`
+	InstallmentID string `json:"installment_id"`
+	TransactionID string `json:"transaction_id"`
+}
+
+// SettleLoanRequest records a single payment that closes the loan: LMS marks
+// every remaining installment paid and writes off Discount.
+type SettleLoanRequest struct {
+	LoanID        string `json:"loan_id"`
+	Amount        int64  `json:"amount"`
+	Discount      int64  `json:"discount"`
+	TransactionID string `json:"transaction_id"`
+}
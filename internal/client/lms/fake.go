@@ -2,13 +2,20 @@ package lms
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
 )
 
 // fakeClient returns static responses that match the agreed-upon API contract
 // with the LMS team. Use this while the real LMS service is still in development.
+// Recorded payments are kept in memory so GetPayments reflects earlier calls.
 type fakeClient struct {
 	logger *slog.Logger
+
+	mu       sync.Mutex
+	payments []Payment
 }
 
 func NewFake(logger *slog.Logger) Client {
@@ -19,36 +26,89 @@ func (f *fakeClient) GetLoan(_ context.Context, loanID string) (*Loan, error) {
 	f.logger.Info("[FAKE LMS] GetLoan", "loan_id", loanID)
 	return &Loan{
 		ID:          loanID,
-		UserID:      "user-aaa-bbb-ccc",
-		Status:      "active",
+		UserID:      fakeLoanOwner(loanID),
+		Status:      LoanStatusActive,
 		PaidAmount:  15000,
 		TotalAmount: 60000,
+
+		EarlyPayoffDiscount: 1000,
 	}, nil
 }
 
-func (f *fakeClient) GetInstallments(_ context.Context, userID string) ([]Installment, error) {
-	f.logger.Info("[FAKE LMS] GetInstallments", "user_id", userID)
-	return []Installment{
-		{ID: "inst-001", LoanID: "loan-001", Amount: 15000, Status: "paid", DueDate: "2026-01-15"},
-		{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-02-15"},
-		{ID: "inst-003", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-03-15"},
-		{ID: "inst-004", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-04-15"},
-	}, nil
+// fakeUserID owns every fixture loan except loan-003, and any loan the
+// fixture does not know, so a single test customer can use the whole flow.
+const (
+	fakeUserID      = "6f1c2a9e-4b7d-4e0a-9c3f-2d8e5b1a7c40"
+	fakeOtherUserID = "b3e7d2c1-8a4f-4f6b-a1d9-5c0e7f2b9d18"
+)
+
+func fakeLoanOwner(loanID string) string {
+	if loanID == "loan-003" {
+		return fakeOtherUserID
+	}
+	return fakeUserID
 }
 
-func (f *fakeClient) GetUpcomingInstallments(_ context.Context) ([]Installment, error) {
-	f.logger.Info("[FAKE LMS] GetUpcomingInstallments")
-	return []Installment{
-		{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-02-15"},
-		{ID: "inst-010", LoanID: "loan-002", Amount: 20000, Status: "upcoming", DueDate: "2026-02-20"},
-	}, nil
+var fakeInstallments = []Installment{
+	{ID: "inst-001", LoanID: "loan-001", Amount: 15000, Currency: "SAR", Status: InstallmentStatusPaid, DueDate: "2026-01-15"},
+	{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Currency: "SAR", Status: InstallmentStatusUpcoming, DueDate: "2026-02-15"},
+	{ID: "inst-003", LoanID: "loan-001", Amount: 15000, Currency: "SAR", Status: InstallmentStatusUpcoming, DueDate: "2026-03-15"},
+	{ID: "inst-004", LoanID: "loan-001", Amount: 15000, Currency: "SAR", Status: InstallmentStatusUpcoming, DueDate: "2026-04-15"},
+	{ID: "inst-010", LoanID: "loan-002", Amount: 20000, Currency: "SAR", Status: InstallmentStatusUpcoming, DueDate: "2026-02-20"},
+	{ID: "inst-007", LoanID: "loan-003", Amount: 12000, Currency: "SAR", Status: InstallmentStatusOverdue, DueDate: "2026-02-01"},
 }
 
-func (f *fakeClient) GetOverdueInstallments(_ context.Context) ([]Installment, error) {
-	f.logger.Info("[FAKE LMS] GetOverdueInstallments")
-	return []Installment{
-		{ID: "inst-007", LoanID: "loan-003", Amount: 12000, Status: "overdue", DueDate: "2026-02-01"},
-	}, nil
+func (f *fakeClient) GetInstallment(_ context.Context, installmentID string) (*Installment, error) {
+	f.logger.Info("[FAKE LMS] GetInstallment", "installment_id", installmentID)
+	for _, inst := range fakeInstallments {
+		if inst.ID == installmentID {
+			return &inst, nil
+		}
+	}
+	return nil, fmt.Errorf("installment %s: %w", installmentID, ErrNotFound)
+}
+
+func (f *fakeClient) GetInstallments(ctx context.Context, userID string) ([]Installment, error) {
+	f.logger.Info("[FAKE LMS] GetInstallments", "user_id", userID)
+	return collectInstallments(ctx, NewInstallmentIterator(f, ListInstallmentsRequest{UserID: userID}))
+}
+
+// ListInstallments pages through the static fixture. The cursor is simply the
+// offset of the next item, which is enough to exercise iterator callers.
+func (f *fakeClient) ListInstallments(_ context.Context, req ListInstallmentsRequest) (*InstallmentPage, error) {
+	f.logger.Info("[FAKE LMS] ListInstallments",
+		"status", req.Status,
+		"user_id", req.UserID,
+		"cursor", req.Cursor,
+		"limit", req.Limit,
+	)
+
+	var matched []Installment
+	for _, inst := range fakeInstallments {
+		if req.UserID != "" && fakeLoanOwner(inst.LoanID) != req.UserID {
+			continue
+		}
+		if req.Status != "" && inst.Status != req.Status {
+			continue
+		}
+		matched = append(matched, inst)
+	}
+
+	offset, _ := strconv.Atoi(req.Cursor)
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	end := min(offset+limit, len(matched))
+
+	page := &InstallmentPage{Items: matched[offset:end]}
+	if end < len(matched) {
+		page.NextCursor = strconv.Itoa(end)
+	}
+	return page, nil
 }
 
 func (f *fakeClient) UpdateLoanStatus(_ context.Context, loanID, status string) error {
@@ -63,5 +123,66 @@ func (f *fakeClient) RecordPayment(_ context.Context, req RecordPaymentRequest)
 		"amount", req.Amount,
 		"transaction_id", req.TransactionID,
 	)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.payments = append(f.payments, Payment{
+		LoanID:        req.LoanID,
+		InstallmentID: req.InstallmentID,
+		Amount:        req.Amount,
+		TransactionID: req.TransactionID,
+	})
+	return nil
+}
+
+func (f *fakeClient) GetPayments(_ context.Context, transactionID string) ([]Payment, error) {
+	f.logger.Info("[FAKE LMS] GetPayments", "transaction_id", transactionID)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payments := []Payment{}
+	for _, p := range f.payments {
+		if p.TransactionID == transactionID {
+			payments = append(payments, p)
+		}
+	}
+	return payments, nil
+}
+
+func (f *fakeClient) ReversePayment(_ context.Context, req ReversePaymentRequest) error {
+	f.logger.Info("[FAKE LMS] ReversePayment",
+		"loan_id", req.LoanID,
+		"installment_id", req.InstallmentID,
+		"transaction_id", req.TransactionID,
+	)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	kept := f.payments[:0]
+	for _, p := range f.payments {
+		if p.TransactionID == req.TransactionID && p.InstallmentID == req.InstallmentID {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	f.payments = kept
+	return nil
+}
+
+func (f *fakeClient) SettleLoan(_ context.Context, req SettleLoanRequest) error {
+	f.logger.Info("[FAKE LMS] SettleLoan",
+		"loan_id", req.LoanID,
+		"amount", req.Amount,
+		"discount", req.Discount,
+		"transaction_id", req.TransactionID,
+	)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.payments = append(f.payments, Payment{
+		LoanID:        req.LoanID,
+		Amount:        req.Amount,
+		TransactionID: req.TransactionID,
+	})
 	return nil
 }
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 type httpClient struct {
@@ -30,6 +32,9 @@ func (c *httpClient) GetLoan(ctx context.Context, loanID string) (*Loan, error)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("loan %s: %w", loanID, ErrNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("LMS returned status %d", resp.StatusCode)
 	}
@@ -41,9 +46,9 @@ func (c *httpClient) GetLoan(ctx context.Context, loanID string) (*Loan, error)
 	return &loan, nil
 }
 
-func (c *httpClient) GetInstallments(ctx context.Context, userID string) ([]Installment, error) {
+func (c *httpClient) GetInstallment(ctx context.Context, installmentID string) (*Installment, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		fmt.Sprintf("%s/loans?user_id=%s", c.baseURL, userID), nil)
+		fmt.Sprintf("%s/installments/%s", c.baseURL, installmentID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("building request: %w", err)
 	}
@@ -54,28 +59,51 @@ func (c *httpClient) GetInstallments(ctx context.Context, userID string) ([]Inst
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("installment %s: %w", installmentID, ErrNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("LMS returned status %d", resp.StatusCode)
 	}
 
-	var installments []Installment
-	if err := json.NewDecoder(resp.Body).Decode(&installments); err != nil {
+	var inst Installment
+	if err := json.NewDecoder(resp.Body).Decode(&inst); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
-	return installments, nil
+	return &inst, nil
 }
 
-func (c *httpClient) GetUpcomingInstallments(ctx context.Context) ([]Installment, error) {
-	return c.listInstallments(ctx, "upcoming")
+// GetInstallments returns every installment of a single user, following
+// pagination until LMS reports no further pages.
+func (c *httpClient) GetInstallments(ctx context.Context, userID string) ([]Installment, error) {
+	return collectInstallments(ctx, NewInstallmentIterator(c, ListInstallmentsRequest{UserID: userID}))
 }
 
-func (c *httpClient) GetOverdueInstallments(ctx context.Context) ([]Installment, error) {
-	return c.listInstallments(ctx, "overdue")
-}
+// ListInstallments fetches one page of installments. A user's installments
+// are served under /loans, as they always have been; only the status
+// listings live under /installments.
+func (c *httpClient) ListInstallments(ctx context.Context, params ListInstallmentsRequest) (*InstallmentPage, error) {
+	path := "/installments"
+	if params.UserID != "" {
+		path = "/loans"
+	}
+
+	query := url.Values{}
+	if params.Status != "" {
+		query.Set("status", params.Status)
+	}
+	if params.UserID != "" {
+		query.Set("user_id", params.UserID)
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
 
-func (c *httpClient) listInstallments(ctx context.Context, status string) ([]Installment, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		fmt.Sprintf("%s/installments?status=%s", c.baseURL, status), nil)
+		fmt.Sprintf("%s%s?%s", c.baseURL, path, query.Encode()), nil)
 	if err != nil {
 		return nil, fmt.Errorf("building request: %w", err)
 	}
@@ -90,11 +118,11 @@ func (c *httpClient) listInstallments(ctx context.Context, status string) ([]Ins
 		return nil, fmt.Errorf("LMS returned status %d", resp.StatusCode)
 	}
 
-	var installments []Installment
-	if err := json.NewDecoder(resp.Body).Decode(&installments); err != nil {
+	var page InstallmentPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
-	return installments, nil
+	return &page, nil
 }
 
 func (c *httpClient) UpdateLoanStatus(ctx context.Context, loanID, status string) error {
@@ -138,3 +166,72 @@ func (c *httpClient) RecordPayment(ctx context.Context, reqBody RecordPaymentReq
 	}
 	return nil
 }
+
+// GetPayments returns every payment LMS has recorded against a PSP
+// transaction (several for a multi-installment checkout).
+func (c *httpClient) GetPayments(ctx context.Context, transactionID string) ([]Payment, error) {
+	query := url.Values{"transaction_id": {transactionID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/payments?%s", c.baseURL, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling LMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LMS returned status %d", resp.StatusCode)
+	}
+
+	var payments []Payment
+	if err := json.NewDecoder(resp.Body).Decode(&payments); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return payments, nil
+}
+
+func (c *httpClient) ReversePayment(ctx context.Context, reqBody ReversePaymentRequest) error {
+	body, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/loans/%s/payments/reversals", c.baseURL, reqBody.LoanID), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling LMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("LMS returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *httpClient) SettleLoan(ctx context.Context, reqBody SettleLoanRequest) error {
+	body, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/loans/%s/settlement", c.baseURL, reqBody.LoanID), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling LMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("LMS returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package lms
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -73,16 +74,75 @@ func TestGetLoan_MalformedJSON(t *testing.T) {
 	}
 }
 
+func TestGetLoan_NotFoundIsErrNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.GetLoan(context.Background(), "missing")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetInstallment_Success(t *testing.T) {
+	expected := Installment{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Currency: "SAR", Status: "upcoming"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/installments/inst-002" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	inst, err := client.GetInstallment(context.Background(), "inst-002")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inst.LoanID != "loan-001" {
+		t.Errorf("expected loan_id=loan-001, got %q", inst.LoanID)
+	}
+	if inst.Currency != "SAR" {
+		t.Errorf("expected currency=SAR, got %q", inst.Currency)
+	}
+}
+
+func TestGetInstallment_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.GetInstallment(context.Background(), "missing")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestGetInstallments_Success(t *testing.T) {
-	expected := []Installment{
-		{ID: "inst-001", LoanID: "loan-001", Amount: 15000, Status: "paid", DueDate: "2026-01-15"},
-		{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-02-15"},
+	expected := InstallmentPage{
+		Items: []Installment{
+			{ID: "inst-001", LoanID: "loan-001", Amount: 15000, Status: "paid", DueDate: "2026-01-15"},
+			{ID: "inst-002", LoanID: "loan-001", Amount: 15000, Status: "upcoming", DueDate: "2026-02-15"},
+		},
 	}
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			t.Errorf("expected GET, got %s", r.Method)
 		}
+		if r.URL.Path != "/loans" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
 		if r.URL.Query().Get("user_id") != "user-123" {
 			t.Errorf("unexpected user_id query param: %s", r.URL.Query().Get("user_id"))
 		}
@@ -105,39 +165,62 @@ func TestGetInstallments_Success(t *testing.T) {
 	}
 }
 
-func TestGetUpcomingInstallments_Success(t *testing.T) {
-	expected := []Installment{
-		{ID: "inst-005", LoanID: "loan-002", Amount: 20000, Status: "upcoming", DueDate: "2026-03-01"},
+func TestGetInstallments_FollowsPages(t *testing.T) {
+	pages := map[string]InstallmentPage{
+		"": {
+			Items:      []Installment{{ID: "inst-001", LoanID: "loan-001"}},
+			NextCursor: "c1",
+		},
+		"c1": {
+			Items: []Installment{{ID: "inst-002", LoanID: "loan-001"}},
+		},
 	}
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Query().Get("status") != "upcoming" {
-			t.Errorf("expected status=upcoming, got %s", r.URL.Query().Get("status"))
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			t.Errorf("unexpected cursor: %s", r.URL.Query().Get("cursor"))
 		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(expected)
+		json.NewEncoder(w).Encode(page)
 	}))
 	defer srv.Close()
 
 	client := NewHTTPClient(srv.URL, srv.Client())
-	installments, err := client.GetUpcomingInstallments(context.Background())
+	installments, err := client.GetInstallments(context.Background(), "user-123")
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(installments) != 1 {
-		t.Fatalf("expected 1 installment, got %d", len(installments))
+	if len(installments) != 2 {
+		t.Fatalf("expected 2 installments, got %d", len(installments))
+	}
+	if installments[1].ID != "inst-002" {
+		t.Errorf("expected second installment ID %q, got %q", "inst-002", installments[1].ID)
 	}
 }
 
-func TestGetOverdueInstallments_Success(t *testing.T) {
-	expected := []Installment{
-		{ID: "inst-009", LoanID: "loan-003", Amount: 12000, Status: "overdue", DueDate: "2026-01-01"},
+func TestListInstallments_Success(t *testing.T) {
+	expected := InstallmentPage{
+		Items: []Installment{
+			{ID: "inst-009", LoanID: "loan-003", Amount: 12000, Status: "overdue", DueDate: "2026-01-01"},
+		},
+		NextCursor: "next-abc",
 	}
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Query().Get("status") != "overdue" {
-			t.Errorf("expected status=overdue, got %s", r.URL.Query().Get("status"))
+		if r.URL.Path != "/installments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("status") != "overdue" {
+			t.Errorf("expected status=overdue, got %s", q.Get("status"))
+		}
+		if q.Get("cursor") != "cur-1" {
+			t.Errorf("expected cursor=cur-1, got %s", q.Get("cursor"))
+		}
+		if q.Get("limit") != "50" {
+			t.Errorf("expected limit=50, got %s", q.Get("limit"))
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(expected)
@@ -145,16 +228,64 @@ func TestGetOverdueInstallments_Success(t *testing.T) {
 	defer srv.Close()
 
 	client := NewHTTPClient(srv.URL, srv.Client())
-	installments, err := client.GetOverdueInstallments(context.Background())
+	page, err := client.ListInstallments(context.Background(), ListInstallmentsRequest{
+		Status: "overdue",
+		Cursor: "cur-1",
+		Limit:  50,
+	})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(installments) != 1 {
-		t.Fatalf("expected 1 installment, got %d", len(installments))
+	if len(page.Items) != 1 {
+		t.Fatalf("expected 1 installment, got %d", len(page.Items))
+	}
+	if page.NextCursor != "next-abc" {
+		t.Errorf("expected next_cursor=next-abc, got %q", page.NextCursor)
+	}
+}
+
+func TestListInstallments_Non200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.ListInstallments(context.Background(), ListInstallmentsRequest{Status: "overdue"})
+
+	if err == nil {
+		t.Fatal("expected error for 503 response")
+	}
+}
+
+func TestInstallmentIterator_StopsOnPageError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(InstallmentPage{
+			Items:      []Installment{{ID: "inst-001"}, {ID: "inst-002"}},
+			NextCursor: "c1",
+		})
+	}))
+	defer srv.Close()
+
+	it := NewInstallmentIterator(NewHTTPClient(srv.URL, srv.Client()), ListInstallmentsRequest{Status: "overdue"})
+	seen := 0
+	for it.Next(context.Background()) {
+		seen++
 	}
-	if installments[0].Status != "overdue" {
-		t.Errorf("expected status %q, got %q", "overdue", installments[0].Status)
+
+	if seen != 2 {
+		t.Errorf("expected 2 installments before the failing page, got %d", seen)
+	}
+	if it.Err() == nil {
+		t.Fatal("expected iterator error after failing page")
 	}
 }
 
@@ -261,6 +392,118 @@ func TestRecordPayment_Non200(t *testing.T) {
 	}
 }
 
+func TestGetPayments_Success(t *testing.T) {
+	expected := []Payment{
+		{LoanID: "loan-001", InstallmentID: "inst-002", Amount: 15000, TransactionID: "txn-abc"},
+		{LoanID: "loan-001", InstallmentID: "inst-003", Amount: 15000, TransactionID: "txn-abc"},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/payments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("transaction_id") != "txn-abc" {
+			t.Errorf("expected transaction_id=txn-abc, got %s", r.URL.Query().Get("transaction_id"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	payments, err := client.GetPayments(context.Background(), "txn-abc")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payments) != 2 {
+		t.Fatalf("expected 2 payments, got %d", len(payments))
+	}
+}
+
+func TestReversePayment_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/loans/loan-001/payments/reversals" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req ReversePaymentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.InstallmentID != "inst-002" {
+			t.Errorf("expected installment_id=inst-002, got %q", req.InstallmentID)
+		}
+		if req.TransactionID != "txn-abc" {
+			t.Errorf("expected transaction_id=txn-abc, got %q", req.TransactionID)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	err := client.ReversePayment(context.Background(), ReversePaymentRequest{
+		LoanID:        "loan-001",
+		InstallmentID: "inst-002",
+		TransactionID: "txn-abc",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSettleLoan_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/loans/loan-001/settlement" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req SettleLoanRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Amount != 44000 {
+			t.Errorf("expected amount=44000, got %d", req.Amount)
+		}
+		if req.Discount != 1000 {
+			t.Errorf("expected discount=1000, got %d", req.Discount)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	err := client.SettleLoan(context.Background(), SettleLoanRequest{
+		LoanID:        "loan-001",
+		Amount:        44000,
+		Discount:      1000,
+		TransactionID: "txn-abc",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSettleLoan_Non200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	err := client.SettleLoan(context.Background(), SettleLoanRequest{LoanID: "loan-001", Amount: 44000})
+
+	if err == nil {
+		t.Fatal("expected error for 409 response")
+	}
+}
+
 func TestGetLoan_ServerDown(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
 	srv.Close() // close immediately to simulate unreachable server
@@ -0,0 +1,71 @@
+package lms
+
+import "context"
+
+const DefaultPageSize = 100
+
+// InstallmentIterator streams a paginated installment listing, fetching the
+// next page from LMS only once the current one has been consumed:
+//
+//	it := lms.NewInstallmentIterator(client, lms.ListInstallmentsRequest{Status: "overdue"})
+//	for it.Next(ctx) {
+//		inst := it.Installment()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type InstallmentIterator struct {
+	client Client
+	req    ListInstallmentsRequest
+	page   []Installment
+	pos    int
+	cur    Installment
+	done   bool
+	err    error
+}
+
+func NewInstallmentIterator(client Client, req ListInstallmentsRequest) *InstallmentIterator {
+	if req.Limit <= 0 {
+		req.Limit = DefaultPageSize
+	}
+	return &InstallmentIterator{client: client, req: req}
+}
+
+// Next advances to the next installment, returning false when the listing is
+// exhausted or a page could not be fetched (check Err).
+func (it *InstallmentIterator) Next(ctx context.Context) bool {
+	for it.pos >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		page, err := it.client.ListInstallments(ctx, it.req)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.page, it.pos = page.Items, 0
+		it.req.Cursor = page.NextCursor
+		it.done = page.NextCursor == ""
+	}
+
+	it.cur = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *InstallmentIterator) Installment() Installment { return it.cur }
+
+func (it *InstallmentIterator) Err() error { return it.err }
+
+// collectInstallments drains an iterator into a slice. Only use it for
+// listings known to be small, such as a single user's installments.
+func collectInstallments(ctx context.Context, it *InstallmentIterator) ([]Installment, error) {
+	installments := []Installment{}
+	for it.Next(ctx) {
+		installments = append(installments, it.Installment())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
@@ -1,8 +1,21 @@
 package psp
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned (wrapped) when the PSP answers 404 for a
+// transaction lookup.
+var ErrNotFound = errors.New("not found in PSP")
 
 type Client interface {
 	Charge(ctx context.Context, req ChargeRequest) (*ChargeResponse, error)
 	Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error)
+	Authorize(ctx context.Context, req AuthorizeRequest) (*AuthorizeResponse, error)
+	Capture(ctx context.Context, req CaptureRequest) (*CaptureResponse, error)
+	Void(ctx context.Context, req VoidRequest) (*VoidResponse, error)
+	VerifyCard(ctx context.Context, req VerifyCardRequest) (*VerifyCardResponse, error)
+	GetTransaction(ctx context.Context, transactionID string) (*Transaction, error)
+	ListTransactions(ctx context.Context, req ListTransactionsRequest) (*TransactionPage, error)
 }
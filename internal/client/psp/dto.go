@@ -1,5 +1,7 @@
 package psp
 
+import "time"
+
 type ChargeRequest struct {
 	Amount    int64  `json:"amount"`
 	Currency  string `json:"currency"`
@@ -7,18 +9,104 @@ type ChargeRequest struct {
 }
 
 type ChargeResponse struct {
-	TransactionID string `json:"transaction_id"`
-	Status        string `json:"status"`
+	TransactionID string       `json:"transaction_id"`
+	Status        ChargeStatus `json:"status"`
+	DeclineCode   string       `json:"decline_code,omitempty"`
 }
 
+// RefundRequest refunds money to a card. TransactionID is optional and ties
+// the refund to a specific earlier charge when there is no order to refer to.
 type RefundRequest struct {
-	OrderID   string `json:"order_id"`
-	Amount    int64  `json:"amount"`
-	Currency  string `json:"currency"`
-	CardToken string `json:"card_token"`
+	OrderID       string `json:"order_id,omitempty"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	CardToken     string `json:"card_token"`
 }
 
 type RefundResponse struct {
-	RefundID string `json:"refund_id"`
-	Status   string `json:"status"`
+	RefundID string       `json:"refund_id"`
+	Status   RefundStatus `json:"status"`
+}
+
+// AuthorizeRequest reserves funds on the card without moving them. The
+// returned TransactionID stays the same through Capture or Void. OffSession
+// tells the PSP the customer is not present to complete a challenge.
+type AuthorizeRequest struct {
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	CardToken  string `json:"card_token"`
+	OffSession bool   `json:"off_session,omitempty"`
+}
+
+// AuthorizeResponse carries a DeclineCode when Status is declined and a
+// RedirectURL when it is requires_action (3-D Secure). A pending status means
+// the issuer answers later, via webhook or GetTransaction.
+type AuthorizeResponse struct {
+	TransactionID string       `json:"transaction_id"`
+	Status        ChargeStatus `json:"status"`
+	DeclineCode   string       `json:"decline_code,omitempty"`
+	RedirectURL   string       `json:"redirect_url,omitempty"`
+}
+
+// CaptureRequest settles an authorization. Amount may be lower than the
+// authorized amount for a partial capture.
+type CaptureRequest struct {
+	TransactionID string `json:"-"`
+	Amount        int64  `json:"amount"`
+}
+
+type CaptureResponse struct {
+	TransactionID string       `json:"transaction_id"`
+	Status        ChargeStatus `json:"status"`
+}
+
+// VoidRequest releases an authorization that will never be captured.
+type VoidRequest struct {
+	TransactionID string `json:"-"`
+}
+
+type VoidResponse struct {
+	TransactionID string       `json:"transaction_id"`
+	Status        ChargeStatus `json:"status"`
+}
+
+// VerifyCardRequest asks the issuer to approve a zero-amount authorization,
+// proving the card can be charged later without moving any money.
+type VerifyCardRequest struct {
+	CardToken string `json:"card_token"`
+	Currency  string `json:"currency"`
+}
+
+type VerifyCardResponse struct {
+	VerificationID string             `json:"verification_id"`
+	Status         VerificationStatus `json:"status"`
+	DeclineCode    string             `json:"decline_code,omitempty"`
+}
+
+// Transaction is the PSP's own record of a charge or refund. For refunds ID
+// is the refund ID and Status holds a RefundStatus, otherwise a ChargeStatus.
+// DeclineCode is set when a charge was declined.
+type Transaction struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	DeclineCode string    `json:"decline_code,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListTransactionsRequest selects transactions created in [From, To). Cursor
+// is the NextCursor of the previous page; leave it empty for the first.
+type ListTransactionsRequest struct {
+	From   time.Time
+	To     time.Time
+	Cursor string
+	Limit  int
+}
+
+type TransactionPage struct {
+	Items      []Transaction `json:"items"`
+	NextCursor string        `json:"next_cursor"`
 }
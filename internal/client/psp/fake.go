@@ -4,17 +4,44 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/example/ppo/pkg/redact"
 )
 
 // fakeClient returns static responses that match the agreed-upon API contract
 // with the PSP team. Use this while the real PSP service is still in development.
+// It remembers the transactions it has produced (in memory only) so that
+// transaction lookups and listings stay consistent with earlier charges.
 type fakeClient struct {
 	logger *slog.Logger
+
+	mu           sync.Mutex
+	transactions map[string]Transaction
 }
 
+// Card tokens ending in these suffixes make the fake decline the
+// authorization (or verification) or ask for a 3-D Secure challenge, so those paths can be
+// exercised locally.
+const (
+	fakeDeclinedSuffix  = "-declined"
+	fakeChallengeSuffix = "-3ds"
+)
+
 func NewFake(logger *slog.Logger) Client {
-	return &fakeClient{logger: logger}
+	return &fakeClient{
+		logger:       logger,
+		transactions: make(map[string]Transaction),
+	}
+}
+
+func (f *fakeClient) remember(txn Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.transactions[txn.ID] = txn
 }
 
 func (f *fakeClient) Charge(_ context.Context, req ChargeRequest) (*ChargeResponse, error) {
@@ -22,12 +49,20 @@ func (f *fakeClient) Charge(_ context.Context, req ChargeRequest) (*ChargeRespon
 	f.logger.Info("[FAKE PSP] Charge",
 		"amount", req.Amount,
 		"currency", req.Currency,
-		"card_token", req.CardToken,
+		"card_token", redact.CardToken(req.CardToken),
 		"transaction_id", txnID,
 	)
+	f.remember(Transaction{
+		ID:        txnID,
+		Type:      "charge",
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Status:    string(ChargeStatusCaptured),
+		CreatedAt: time.Now().UTC(),
+	})
 	return &ChargeResponse{
 		TransactionID: txnID,
-		Status:        "captured",
+		Status:        ChargeStatusCaptured,
 	}, nil
 }
 
@@ -35,12 +70,172 @@ func (f *fakeClient) Refund(_ context.Context, req RefundRequest) (*RefundRespon
 	refundID := fmt.Sprintf("fake-ref-%d", time.Now().UnixMilli())
 	f.logger.Info("[FAKE PSP] Refund",
 		"order_id", req.OrderID,
+		"transaction_id", req.TransactionID,
 		"amount", req.Amount,
 		"currency", req.Currency,
 		"refund_id", refundID,
 	)
+	f.remember(Transaction{
+		ID:        refundID,
+		Type:      "refund",
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Status:    string(RefundStatusRefunded),
+		CreatedAt: time.Now().UTC(),
+	})
 	return &RefundResponse{
 		RefundID: refundID,
-		Status:   "refunded",
+		Status:   RefundStatusRefunded,
+	}, nil
+}
+
+func (f *fakeClient) Authorize(_ context.Context, req AuthorizeRequest) (*AuthorizeResponse, error) {
+	txnID := fmt.Sprintf("fake-txn-%d", time.Now().UnixMilli())
+	f.logger.Info("[FAKE PSP] Authorize",
+		"amount", req.Amount,
+		"currency", req.Currency,
+		"card_token", redact.CardToken(req.CardToken),
+		"transaction_id", txnID,
+	)
+	if strings.HasSuffix(req.CardToken, fakeDeclinedSuffix) {
+		f.remember(Transaction{
+			ID:          txnID,
+			Type:        "charge",
+			Amount:      req.Amount,
+			Currency:    req.Currency,
+			Status:      string(ChargeStatusDeclined),
+			DeclineCode: "insufficient_funds",
+			CreatedAt:   time.Now().UTC(),
+		})
+		return &AuthorizeResponse{
+			TransactionID: txnID,
+			Status:        ChargeStatusDeclined,
+			DeclineCode:   "insufficient_funds",
+		}, nil
+	}
+	if strings.HasSuffix(req.CardToken, fakeChallengeSuffix) && !req.OffSession {
+		f.remember(Transaction{
+			ID:        txnID,
+			Type:      "charge",
+			Amount:    req.Amount,
+			Currency:  req.Currency,
+			Status:    string(ChargeStatusRequiresAction),
+			CreatedAt: time.Now().UTC(),
+		})
+		return &AuthorizeResponse{
+			TransactionID: txnID,
+			Status:        ChargeStatusRequiresAction,
+			RedirectURL:   fmt.Sprintf("https://fake-psp.local/3ds/%s", txnID),
+		}, nil
+	}
+	f.remember(Transaction{
+		ID:        txnID,
+		Type:      "charge",
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Status:    string(ChargeStatusAuthorized),
+		CreatedAt: time.Now().UTC(),
+	})
+	return &AuthorizeResponse{
+		TransactionID: txnID,
+		Status:        ChargeStatusAuthorized,
+	}, nil
+}
+
+func (f *fakeClient) Capture(_ context.Context, req CaptureRequest) (*CaptureResponse, error) {
+	f.logger.Info("[FAKE PSP] Capture", "transaction_id", req.TransactionID, "amount", req.Amount)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	txn, ok := f.transactions[req.TransactionID]
+	if !ok || ChargeStatus(txn.Status) != ChargeStatusAuthorized {
+		return nil, fmt.Errorf("transaction %s is not capturable", req.TransactionID)
+	}
+	txn.Status = string(ChargeStatusCaptured)
+	if req.Amount > 0 {
+		txn.Amount = req.Amount
+	}
+	f.transactions[txn.ID] = txn
+
+	return &CaptureResponse{
+		TransactionID: txn.ID,
+		Status:        ChargeStatusCaptured,
+	}, nil
+}
+
+func (f *fakeClient) Void(_ context.Context, req VoidRequest) (*VoidResponse, error) {
+	f.logger.Info("[FAKE PSP] Void", "transaction_id", req.TransactionID)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	txn, ok := f.transactions[req.TransactionID]
+	if !ok || (ChargeStatus(txn.Status) != ChargeStatusAuthorized && ChargeStatus(txn.Status) != ChargeStatusRequiresAction) {
+		return nil, fmt.Errorf("transaction %s is not voidable", req.TransactionID)
+	}
+	txn.Status = string(ChargeStatusVoided)
+	f.transactions[txn.ID] = txn
+
+	return &VoidResponse{
+		TransactionID: txn.ID,
+		Status:        ChargeStatusVoided,
+	}, nil
+}
+
+func (f *fakeClient) VerifyCard(_ context.Context, req VerifyCardRequest) (*VerifyCardResponse, error) {
+	verificationID := fmt.Sprintf("fake-ver-%d", time.Now().UnixMilli())
+	f.logger.Info("[FAKE PSP] VerifyCard",
+		"currency", req.Currency,
+		"card_token", redact.CardToken(req.CardToken),
+		"verification_id", verificationID,
+	)
+	if strings.HasSuffix(req.CardToken, fakeDeclinedSuffix) {
+		return &VerifyCardResponse{
+			VerificationID: verificationID,
+			Status:         VerificationStatusDeclined,
+			DeclineCode:    "expired_card",
+		}, nil
+	}
+	return &VerifyCardResponse{
+		VerificationID: verificationID,
+		Status:         VerificationStatusVerified,
 	}, nil
 }
+
+func (f *fakeClient) GetTransaction(_ context.Context, transactionID string) (*Transaction, error) {
+	f.logger.Info("[FAKE PSP] GetTransaction", "transaction_id", transactionID)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	txn, ok := f.transactions[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s: %w", transactionID, ErrNotFound)
+	}
+	// there is no real challenge page: the customer passes it as soon as
+	// anyone asks how it went
+	if ChargeStatus(txn.Status) == ChargeStatusRequiresAction {
+		txn.Status = string(ChargeStatusAuthorized)
+		f.transactions[txn.ID] = txn
+	}
+	return &txn, nil
+}
+
+// ListTransactions returns every remembered transaction in the window as a
+// single page.
+func (f *fakeClient) ListTransactions(_ context.Context, req ListTransactionsRequest) (*TransactionPage, error) {
+	f.logger.Info("[FAKE PSP] ListTransactions", "from", req.From, "to", req.To)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items := []Transaction{}
+	for _, txn := range f.transactions {
+		if !req.From.IsZero() && txn.CreatedAt.Before(req.From) {
+			continue
+		}
+		if !req.To.IsZero() && !txn.CreatedAt.Before(req.To) {
+			continue
+		}
+		items = append(items, txn)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt.Before(items[j].CreatedAt) })
+	return &TransactionPage{Items: items}, nil
+}
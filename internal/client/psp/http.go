@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type httpClient struct {
@@ -68,3 +71,125 @@ func (c *httpClient) Refund(ctx context.Context, reqBody RefundRequest) (*Refund
 	}
 	return &refundResp, nil
 }
+
+func (c *httpClient) Authorize(ctx context.Context, reqBody AuthorizeRequest) (*AuthorizeResponse, error) {
+	var authResp AuthorizeResponse
+	if err := c.post(ctx, fmt.Sprintf("%s/authorizations", c.baseURL), reqBody, &authResp); err != nil {
+		return nil, err
+	}
+	return &authResp, nil
+}
+
+func (c *httpClient) Capture(ctx context.Context, reqBody CaptureRequest) (*CaptureResponse, error) {
+	var captureResp CaptureResponse
+	if err := c.post(ctx, fmt.Sprintf("%s/transactions/%s/capture", c.baseURL, reqBody.TransactionID), reqBody, &captureResp); err != nil {
+		return nil, err
+	}
+	return &captureResp, nil
+}
+
+func (c *httpClient) Void(ctx context.Context, reqBody VoidRequest) (*VoidResponse, error) {
+	var voidResp VoidResponse
+	if err := c.post(ctx, fmt.Sprintf("%s/transactions/%s/void", c.baseURL, reqBody.TransactionID), reqBody, &voidResp); err != nil {
+		return nil, err
+	}
+	return &voidResp, nil
+}
+
+func (c *httpClient) VerifyCard(ctx context.Context, reqBody VerifyCardRequest) (*VerifyCardResponse, error) {
+	var verifyResp VerifyCardResponse
+	if err := c.post(ctx, fmt.Sprintf("%s/card-verifications", c.baseURL), reqBody, &verifyResp); err != nil {
+		return nil, err
+	}
+	return &verifyResp, nil
+}
+
+// post sends a JSON body and decodes a JSON answer, accepting 200 and 201.
+func (c *httpClient) post(ctx context.Context, endpoint string, reqBody, out any) error {
+	body, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling PSP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("PSP returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func (c *httpClient) GetTransaction(ctx context.Context, transactionID string) (*Transaction, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/transactions/%s", c.baseURL, transactionID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling PSP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("transaction %s: %w", transactionID, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PSP returned status %d", resp.StatusCode)
+	}
+
+	var txn Transaction
+	if err := json.NewDecoder(resp.Body).Decode(&txn); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &txn, nil
+}
+
+func (c *httpClient) ListTransactions(ctx context.Context, params ListTransactionsRequest) (*TransactionPage, error) {
+	query := url.Values{}
+	if !params.From.IsZero() {
+		query.Set("from", params.From.UTC().Format(time.RFC3339))
+	}
+	if !params.To.IsZero() {
+		query.Set("to", params.To.UTC().Format(time.RFC3339))
+	}
+	if params.Cursor != "" {
+		query.Set("cursor", params.Cursor)
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/transactions?%s", c.baseURL, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling PSP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PSP returned status %d", resp.StatusCode)
+	}
+
+	var page TransactionPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return &page, nil
+}
@@ -3,10 +3,12 @@ package psp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestCharge_Success(t *testing.T) {
@@ -162,6 +164,305 @@ func TestRefund_Non200(t *testing.T) {
 	}
 }
 
+func TestAuthorize_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/authorizations" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req AuthorizeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Amount != 25000 {
+			t.Errorf("expected amount=25000, got %d", req.Amount)
+		}
+		if req.CardToken != "tok-abc" {
+			t.Errorf("expected card_token=tok-abc, got %q", req.CardToken)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(AuthorizeResponse{TransactionID: "txn-123", Status: "authorized"})
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.Authorize(context.Background(), AuthorizeRequest{
+		Amount:    25000,
+		Currency:  "SAR",
+		CardToken: "tok-abc",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.TransactionID != "txn-123" {
+		t.Errorf("expected transaction_id=txn-123, got %q", resp.TransactionID)
+	}
+}
+
+func TestAuthorize_Non200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.Authorize(context.Background(), AuthorizeRequest{Amount: 25000, Currency: "SAR", CardToken: "tok-abc"})
+
+	if err == nil {
+		t.Fatal("expected error for 402 response")
+	}
+}
+
+func TestAuthorize_Declined(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"transaction_id":"txn-9","status":"declined","decline_code":"insufficient_funds"}`))
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.Authorize(context.Background(), AuthorizeRequest{Amount: 25000, Currency: "SAR", CardToken: "tok-abc"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != ChargeStatusDeclined {
+		t.Errorf("expected status=declined, got %q", resp.Status)
+	}
+	if resp.DeclineCode != "insufficient_funds" {
+		t.Errorf("expected decline_code=insufficient_funds, got %q", resp.DeclineCode)
+	}
+}
+
+func TestAuthorize_RequiresAction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["off_session"]; ok {
+			t.Errorf("expected off_session to be omitted for on-session requests")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"transaction_id":"txn-7","status":"requires_action","redirect_url":"https://psp.example/3ds/txn-7"}`))
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.Authorize(context.Background(), AuthorizeRequest{Amount: 25000, Currency: "SAR", CardToken: "tok-abc"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != ChargeStatusRequiresAction {
+		t.Errorf("expected status=requires_action, got %q", resp.Status)
+	}
+	if resp.RedirectURL != "https://psp.example/3ds/txn-7" {
+		t.Errorf("unexpected redirect_url: %q", resp.RedirectURL)
+	}
+}
+
+func TestCapture_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transactions/txn-123/capture" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["amount"] != float64(25000) {
+			t.Errorf("expected amount=25000, got %v", body["amount"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(CaptureResponse{TransactionID: "txn-123", Status: "captured"})
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.Capture(context.Background(), CaptureRequest{TransactionID: "txn-123", Amount: 25000})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != "captured" {
+		t.Errorf("expected status=captured, got %q", resp.Status)
+	}
+}
+
+func TestVoid_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/transactions/txn-123/void" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VoidResponse{TransactionID: "txn-123", Status: "voided"})
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.Void(context.Background(), VoidRequest{TransactionID: "txn-123"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != "voided" {
+		t.Errorf("expected status=voided, got %q", resp.Status)
+	}
+}
+
+func TestVoid_Non200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.Void(context.Background(), VoidRequest{TransactionID: "txn-123"})
+
+	if err == nil {
+		t.Fatal("expected error for 409 response")
+	}
+}
+
+func TestVerifyCard_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if r.URL.Path != "/card-verifications" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req VerifyCardRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.CardToken != "tok-new" {
+			t.Errorf("expected card_token=tok-new, got %q", req.CardToken)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VerifyCardResponse{VerificationID: "ver-1", Status: VerificationStatusVerified})
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	resp, err := client.VerifyCard(context.Background(), VerifyCardRequest{CardToken: "tok-new", Currency: "SAR"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != VerificationStatusVerified {
+		t.Errorf("expected status=verified, got %q", resp.Status)
+	}
+	if resp.VerificationID != "ver-1" {
+		t.Errorf("expected verification_id=ver-1, got %q", resp.VerificationID)
+	}
+}
+
+func TestVerifyCard_Non200(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.VerifyCard(context.Background(), VerifyCardRequest{CardToken: "tok-new", Currency: "SAR"})
+
+	if err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestGetTransaction_Success(t *testing.T) {
+	expected := Transaction{ID: "txn-123", Type: "charge", Amount: 25000, Currency: "SAR", Status: "captured"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET, got %s", r.Method)
+		}
+		if r.URL.Path != "/transactions/txn-123" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expected)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	txn, err := client.GetTransaction(context.Background(), "txn-123")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if txn.Amount != 25000 {
+		t.Errorf("expected amount=25000, got %d", txn.Amount)
+	}
+}
+
+func TestGetTransaction_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	_, err := client.GetTransaction(context.Background(), "missing")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListTransactions_Success(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transactions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("from") != "2026-03-01T00:00:00Z" {
+			t.Errorf("unexpected from: %s", q.Get("from"))
+		}
+		if q.Get("to") != "2026-03-02T00:00:00Z" {
+			t.Errorf("unexpected to: %s", q.Get("to"))
+		}
+		if q.Get("cursor") != "c1" {
+			t.Errorf("expected cursor=c1, got %s", q.Get("cursor"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TransactionPage{
+			Items:      []Transaction{{ID: "txn-1"}, {ID: "ref-1", Type: "refund"}},
+			NextCursor: "c2",
+		})
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL, srv.Client())
+	page, err := client.ListTransactions(context.Background(), ListTransactionsRequest{
+		From:   from,
+		To:     to,
+		Cursor: "c1",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(page.Items))
+	}
+	if page.NextCursor != "c2" {
+		t.Errorf("expected next_cursor=c2, got %q", page.NextCursor)
+	}
+}
+
 func TestCharge_ServerDown(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
 	srv.Close()
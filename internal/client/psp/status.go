@@ -0,0 +1,59 @@
+package psp
+
+import "fmt"
+
+// ChargeStatus is the PSP's state for a charge or authorization.
+type ChargeStatus string
+
+const (
+	ChargeStatusPending        ChargeStatus = "pending"
+	ChargeStatusRequiresAction ChargeStatus = "requires_action"
+	ChargeStatusAuthorized     ChargeStatus = "authorized"
+	ChargeStatusCaptured       ChargeStatus = "captured"
+	ChargeStatusDeclined       ChargeStatus = "declined"
+	ChargeStatusFailed         ChargeStatus = "failed"
+	ChargeStatusVoided         ChargeStatus = "voided"
+)
+
+// Final reports whether the charge can no longer change state.
+func (s ChargeStatus) Final() bool {
+	switch s {
+	case ChargeStatusCaptured, ChargeStatusDeclined, ChargeStatusFailed, ChargeStatusVoided:
+		return true
+	}
+	return false
+}
+
+// RefundStatus is the PSP's state for a refund.
+type RefundStatus string
+
+const (
+	RefundStatusPending  RefundStatus = "pending"
+	RefundStatusRefunded RefundStatus = "refunded"
+	RefundStatusFailed   RefundStatus = "failed"
+)
+
+func (s RefundStatus) Final() bool {
+	return s == RefundStatusRefunded || s == RefundStatusFailed
+}
+
+// VerificationStatus is the result of a zero-amount card check.
+type VerificationStatus string
+
+const (
+	VerificationStatusVerified VerificationStatus = "verified"
+	VerificationStatusDeclined VerificationStatus = "declined"
+)
+
+// DeclineError is returned when the card issuer refuses a charge. Code is
+// the PSP's machine-readable reason, e.g. "insufficient_funds".
+type DeclineError struct {
+	Code string
+}
+
+func (e *DeclineError) Error() string {
+	if e.Code == "" {
+		return "card declined"
+	}
+	return fmt.Sprintf("card declined: %s", e.Code)
+}
@@ -1,14 +1,63 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type Config struct {
-	Port           int    `envconfig:"PORT" default:"8080"`
+	Port int `envconfig:"PORT" default:"8080"`
+	// AdminPort serves /admin/v1 on its own listener, e.g. one reachable only
+	// from the internal network. Zero keeps it on Port.
+	AdminPort      int    `envconfig:"ADMIN_PORT" default:"0"`
 	DatabaseURL    string `envconfig:"DATABASE_URL" required:"true"`
 	UseFakeClients bool   `envconfig:"USE_FAKE_CLIENTS" default:"true"`
 	LMSBaseURL     string `envconfig:"LMS_BASE_URL" default:"http://localhost:8081"`
 	PSPBaseURL     string `envconfig:"PSP_BASE_URL" default:"http://localhost:8082"`
 	ProductBaseURL string `envconfig:"PRODUCT_BASE_URL" default:"http://localhost:8083"`
+
+	// AutoChargeDryRun makes every auto-charge run (scheduled or manual)
+	// report what it would charge without touching PSP or LMS.
+	AutoChargeDryRun bool `envconfig:"AUTO_CHARGE_DRY_RUN" default:"false"`
+
+	// PSPWebhookSecret signs PSP webhook deliveries. While it is empty every
+	// delivery is rejected.
+	PSPWebhookSecret    string        `envconfig:"PSP_WEBHOOK_SECRET"`
+	PSPWebhookTolerance time.Duration `envconfig:"PSP_WEBHOOK_TOLERANCE" default:"5m"`
+
+	// Card tokens are encrypted at rest with keys from CardKeysFile (JSON)
+	// or, when it is empty, from CardKeys ("id:base64,...") with CardKeyID
+	// naming the key new values are encrypted with.
+	CardKeys     string `envconfig:"CARD_KEYS"`
+	CardKeyID    string `envconfig:"CARD_KEY_ID"`
+	CardKeysFile string `envconfig:"CARD_KEYS_FILE"`
+
+	// API callers authenticate with a bearer JWT signed either with
+	// AuthJWTSecret (HS256, for development) or by a key in AuthJWKSFile
+	// (RS256). While neither is set every API request is rejected.
+	AuthJWTSecret string `envconfig:"AUTH_JWT_SECRET"`
+	AuthJWKSFile  string `envconfig:"AUTH_JWKS_FILE"`
+	AuthIssuer    string `envconfig:"AUTH_ISSUER"`
+	AuthAudience  string `envconfig:"AUTH_AUDIENCE"`
+
+	// Token-bucket rate limits for /api/v1, in requests per minute; 0 turns a
+	// limit off. RateLimitRoutes adds per-user limits for single routes as
+	// "<METHOD> <route>=<n>" separated by ";". RateLimitStore is memory (per
+	// replica) or postgres (shared by all replicas).
+	RateLimitStore   string `envconfig:"RATE_LIMIT_STORE" default:"memory"`
+	RateLimitPerIP   int    `envconfig:"RATE_LIMIT_PER_IP" default:"300"`
+	RateLimitPerUser int    `envconfig:"RATE_LIMIT_PER_USER" default:"120"`
+	RateLimitRoutes  string `envconfig:"RATE_LIMIT_ROUTES" default:"POST /api/v1/installments/pay=10;POST /api/v1/installments/pay-batch=10;POST /api/v1/loans/:loanId/payoff=5;POST /api/v1/orders/:id/cancel=5"`
+
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For is
+	// believed when finding a client's IP. Empty trusts none, so the per-IP
+	// limit uses the connection's peer address.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+
+	// LogRedactAllow lists log attribute keys (such as user_id) that are
+	// logged unredacted. Leave it empty in production.
+	LogRedactAllow []string `envconfig:"LOG_REDACT_ALLOW"`
 }
 
 func Load() (*Config, error) {
@@ -0,0 +1,118 @@
+package ledger
+
+import "github.com/google/uuid"
+
+// BalanceQuery is bound from GET /ledger/balances. Account is an account
+// code such as "psp_clearing".
+type BalanceQuery struct {
+	Account  string `form:"account"`
+	UserID   string `form:"user_id"`
+	LoanID   string `form:"loan_id"`
+	Currency string `form:"currency" binding:"omitempty,len=3"`
+}
+
+type BalanceResponse struct {
+	Currency string                   `json:"currency"`
+	Total    int64                    `json:"total"`
+	Accounts []AccountBalanceResponse `json:"accounts"`
+}
+
+type AccountBalanceResponse struct {
+	Code    string      `json:"code"`
+	Type    AccountType `json:"type"`
+	UserID  string      `json:"user_id,omitempty"`
+	LoanID  string      `json:"loan_id,omitempty"`
+	Balance int64       `json:"balance"`
+}
+
+type TrialBalanceResponse struct {
+	Currency     string             `json:"currency"`
+	Balanced     bool               `json:"balanced"`
+	TotalDebits  int64              `json:"total_debits"`
+	TotalCredits int64              `json:"total_credits"`
+	Accounts     []TrialBalanceLine `json:"accounts"`
+}
+
+type TrialBalanceLine struct {
+	Code   string      `json:"code"`
+	Type   AccountType `json:"type"`
+	Debit  int64       `json:"debit"`
+	Credit int64       `json:"credit"`
+}
+
+type InvariantResponse struct {
+	OK                   bool                    `json:"ok"`
+	UnbalancedEntries    []UnbalancedEntryResult `json:"unbalanced_entries"`
+	UnbalancedCurrencies []string                `json:"unbalanced_currencies"`
+}
+
+type UnbalancedEntryResult struct {
+	EntryID   uuid.UUID `json:"entry_id"`
+	Kind      EntryKind `json:"kind"`
+	Reference string    `json:"reference"`
+	Sum       int64     `json:"sum"`
+}
+
+// ToBalanceResponses groups balances by currency and totals them, giving
+// e.g. a user's outstanding balance across all their loans.
+func ToBalanceResponses(balances []AccountBalance) []BalanceResponse {
+	var out []BalanceResponse
+	idx := map[string]int{}
+	for _, b := range balances {
+		i, ok := idx[b.Currency]
+		if !ok {
+			i = len(out)
+			idx[b.Currency] = i
+			out = append(out, BalanceResponse{Currency: b.Currency, Accounts: []AccountBalanceResponse{}})
+		}
+		out[i].Total += b.Balance
+		out[i].Accounts = append(out[i].Accounts, AccountBalanceResponse{
+			Code:    b.Code,
+			Type:    b.Type,
+			UserID:  b.UserID,
+			LoanID:  b.LoanID,
+			Balance: b.Balance,
+		})
+	}
+	return out
+}
+
+func ToTrialBalanceResponse(tb TrialBalance) TrialBalanceResponse {
+	resp := TrialBalanceResponse{
+		Currency:     tb.Currency,
+		Balanced:     tb.Balanced(),
+		TotalDebits:  tb.TotalDebits,
+		TotalCredits: tb.TotalCredits,
+		Accounts:     make([]TrialBalanceLine, len(tb.Accounts)),
+	}
+	for i, a := range tb.Accounts {
+		line := TrialBalanceLine{Code: a.Code, Type: a.Type}
+		if a.Balance > 0 {
+			line.Debit = a.Balance
+		} else {
+			line.Credit = -a.Balance
+		}
+		resp.Accounts[i] = line
+	}
+	return resp
+}
+
+func ToInvariantResponse(r *InvariantReport) InvariantResponse {
+	resp := InvariantResponse{
+		OK:                   r.OK,
+		UnbalancedEntries:    make([]UnbalancedEntryResult, len(r.UnbalancedEntries)),
+		UnbalancedCurrencies: r.UnbalancedCurrencies,
+	}
+	if resp.UnbalancedCurrencies == nil {
+		resp.UnbalancedCurrencies = []string{}
+	}
+	for i, e := range r.UnbalancedEntries {
+		resp.UnbalancedEntries[i] = UnbalancedEntryResult{
+			EntryID:   e.EntryID,
+			Kind:      e.Kind,
+			Reference: e.Reference,
+			Sum:       e.Sum,
+		}
+	}
+	return resp
+}
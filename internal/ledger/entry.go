@@ -0,0 +1,79 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AccountRef names an account by code; the account is created on first use.
+type AccountRef struct {
+	Code   string
+	Type   AccountType
+	UserID string
+	LoanID string
+}
+
+func PSPClearing() AccountRef {
+	return AccountRef{Code: CodePSPClearing, Type: AccountAsset}
+}
+
+func MerchantPayable() AccountRef {
+	return AccountRef{Code: CodeMerchantPayable, Type: AccountLiability}
+}
+
+func EarlyPayoffDiscount() AccountRef {
+	return AccountRef{Code: CodeEarlyPayoffDiscount, Type: AccountExpense}
+}
+
+// LoanReceivable is what a customer still owes on one loan. userID may be
+// empty when the account already exists.
+func LoanReceivable(loanID, userID string) AccountRef {
+	return AccountRef{Code: codeLoanReceivable + loanID, Type: AccountAsset, UserID: userID, LoanID: loanID}
+}
+
+// Line is one side of an entry; positive amounts are debits, negative
+// amounts credits.
+type Line struct {
+	Account AccountRef
+	Amount  int64
+}
+
+func Debit(acc AccountRef, amount int64) Line  { return Line{Account: acc, Amount: amount} }
+func Credit(acc AccountRef, amount int64) Line { return Line{Account: acc, Amount: -amount} }
+
+type EntryInput struct {
+	Kind        EntryKind
+	Reference   string
+	Description string
+	Currency    string
+	Lines       []Line
+}
+
+// Validate enforces the double-entry invariants before anything is written:
+// at least two lines, no zero amounts, and debits equal to credits.
+func (in EntryInput) Validate() error {
+	if in.Kind == "" || in.Reference == "" {
+		return errors.New("entry kind and reference are required")
+	}
+	if len(in.Currency) != 3 {
+		return fmt.Errorf("invalid currency %q", in.Currency)
+	}
+	if len(in.Lines) < 2 {
+		return errors.New("entry needs at least two lines")
+	}
+
+	var sum int64
+	for _, l := range in.Lines {
+		if l.Account.Code == "" {
+			return errors.New("line has no account")
+		}
+		if l.Amount == 0 {
+			return fmt.Errorf("line for %s has zero amount", l.Account.Code)
+		}
+		sum += l.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("entry is unbalanced by %d", sum)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import "testing"
+
+func TestEntryInputValidate(t *testing.T) {
+	loan := LoanReceivable("loan-001", "user-1")
+
+	tests := []struct {
+		name    string
+		in      EntryInput
+		wantErr bool
+	}{
+		{
+			name: "balanced",
+			in: EntryInput{
+				Kind: KindPaymentReceived, Reference: "p-1", Currency: "SAR",
+				Lines: []Line{Debit(PSPClearing(), 15000), Credit(loan, 15000)},
+			},
+		},
+		{
+			name: "three way balanced",
+			in: EntryInput{
+				Kind: KindLoanCancelled, Reference: "o-1", Currency: "SAR",
+				Lines: []Line{Debit(MerchantPayable(), 60000), Credit(loan, 45000), Credit(PSPClearing(), 15000)},
+			},
+		},
+		{
+			name: "unbalanced",
+			in: EntryInput{
+				Kind: KindPaymentReceived, Reference: "p-1", Currency: "SAR",
+				Lines: []Line{Debit(PSPClearing(), 15000), Credit(loan, 14999)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "single line",
+			in: EntryInput{
+				Kind: KindPaymentReceived, Reference: "p-1", Currency: "SAR",
+				Lines: []Line{Debit(PSPClearing(), 0)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero amount line",
+			in: EntryInput{
+				Kind: KindPaymentReceived, Reference: "p-1", Currency: "SAR",
+				Lines: []Line{Debit(PSPClearing(), 0), Credit(loan, 0)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing reference",
+			in: EntryInput{
+				Kind: KindPaymentReceived, Currency: "SAR",
+				Lines: []Line{Debit(PSPClearing(), 100), Credit(loan, 100)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.in.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
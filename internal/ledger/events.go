@@ -0,0 +1,78 @@
+package ledger
+
+import "fmt"
+
+// The constructors below translate business events into balanced entries.
+// The loan receivable is debited when the customer takes the loan and
+// credited as money comes in, so its balance is always what is still owed.
+
+// OrderCreated books the loan: the customer owes us the total and we owe the
+// merchant the same amount.
+func OrderCreated(orderID, loanID, userID string, amount int64, currency string) EntryInput {
+	return EntryInput{
+		Kind:        KindOrderCreated,
+		Reference:   orderID,
+		Description: fmt.Sprintf("order %s financed by loan %s", orderID, loanID),
+		Currency:    currency,
+		Lines: []Line{
+			Debit(LoanReceivable(loanID, userID), amount),
+			Credit(MerchantPayable(), amount),
+		},
+	}
+}
+
+// PaymentReceived books a captured charge against the loan.
+func PaymentReceived(paymentID, loanID string, amount int64, currency string) EntryInput {
+	return EntryInput{
+		Kind:        KindPaymentReceived,
+		Reference:   paymentID,
+		Description: fmt.Sprintf("payment %s for loan %s", paymentID, loanID),
+		Currency:    currency,
+		Lines: []Line{
+			Debit(PSPClearing(), amount),
+			Credit(LoanReceivable(loanID, ""), amount),
+		},
+	}
+}
+
+// RefundIssued reverses money previously received on the loan.
+func RefundIssued(paymentID, loanID string, amount int64, currency string) EntryInput {
+	return EntryInput{
+		Kind:        KindRefundIssued,
+		Reference:   paymentID,
+		Description: fmt.Sprintf("refund %s for loan %s", paymentID, loanID),
+		Currency:    currency,
+		Lines: []Line{
+			Debit(LoanReceivable(loanID, ""), amount),
+			Credit(PSPClearing(), amount),
+		},
+	}
+}
+
+// LoanCancelled unwinds OrderCreated once the order is cancelled.
+func LoanCancelled(orderID, loanID string, amount int64, currency string) EntryInput {
+	return EntryInput{
+		Kind:        KindLoanCancelled,
+		Reference:   orderID,
+		Description: fmt.Sprintf("order %s cancelled, loan %s closed", orderID, loanID),
+		Currency:    currency,
+		Lines: []Line{
+			Debit(MerchantPayable(), amount),
+			Credit(LoanReceivable(loanID, ""), amount),
+		},
+	}
+}
+
+// PayoffDiscount writes off the part of the balance waived for early payoff.
+func PayoffDiscount(loanID string, amount int64, currency string) EntryInput {
+	return EntryInput{
+		Kind:        KindPayoffDiscount,
+		Reference:   loanID,
+		Description: fmt.Sprintf("early payoff discount on loan %s", loanID),
+		Currency:    currency,
+		Lines: []Line{
+			Debit(EarlyPayoffDiscount(), amount),
+			Credit(LoanReceivable(loanID, ""), amount),
+		},
+	}
+}
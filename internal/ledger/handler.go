@@ -0,0 +1,76 @@
+package ledger
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	ledger := rg.Group("/ledger")
+	ledger.GET("/balances", h.GetBalances)
+	ledger.GET("/trial-balance", h.GetTrialBalance)
+	ledger.GET("/invariants", h.CheckInvariants)
+}
+
+func (h *Handler) GetBalances(c *gin.Context) {
+	var q BalanceQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+	if q.Account == "" && q.UserID == "" && q.LoanID == "" {
+		_ = c.Error(apperror.NewInvalidRequest("one of account, user_id or loan_id is required",
+			apperror.Field("account", "required_without_all", "param", "user_id, loan_id"),
+			apperror.Field("user_id", "required_without_all", "param", "account, loan_id"),
+			apperror.Field("loan_id", "required_without_all", "param", "account, user_id"),
+		).Localized("ledger.filter_required"))
+		return
+	}
+
+	balances, err := h.svc.Balances(c.Request.Context(), BalanceFilter{
+		Code:     q.Account,
+		UserID:   q.UserID,
+		LoanID:   q.LoanID,
+		Currency: q.Currency,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToBalanceResponses(balances))
+}
+
+func (h *Handler) GetTrialBalance(c *gin.Context) {
+	trial, err := h.svc.TrialBalance(c.Request.Context(), c.Query("currency"))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]TrialBalanceResponse, len(trial))
+	for i, tb := range trial {
+		out[i] = ToTrialBalanceResponse(tb)
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) CheckInvariants(c *gin.Context) {
+	report, err := h.svc.CheckInvariants(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToInvariantResponse(report))
+}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountType string
+
+const (
+	AccountAsset     AccountType = "asset"
+	AccountLiability AccountType = "liability"
+	AccountExpense   AccountType = "expense"
+)
+
+// Well-known account codes. Loan receivables are per loan, see
+// LoanReceivable.
+const (
+	CodePSPClearing         = "psp_clearing"
+	CodeMerchantPayable     = "merchant_payable"
+	CodeEarlyPayoffDiscount = "early_payoff_discount"
+	codeLoanReceivable      = "loan_receivable:"
+)
+
+type EntryKind string
+
+const (
+	KindOrderCreated    EntryKind = "order_created"
+	KindPaymentReceived EntryKind = "payment_received"
+	KindRefundIssued    EntryKind = "refund_issued"
+	KindLoanCancelled   EntryKind = "loan_cancelled"
+	KindPayoffDiscount  EntryKind = "payoff_discount"
+)
+
+type Account struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Code      string      `gorm:"type:varchar(128);not null"`
+	Type      AccountType `gorm:"type:varchar(20);not null"`
+	Currency  string      `gorm:"type:varchar(3);not null"`
+	UserID    string      `gorm:"type:varchar(64);not null;default:''"`
+	LoanID    string      `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt time.Time
+}
+
+// Entry is one balanced journal entry. (Kind, Reference) is unique, which
+// makes posting the same business event twice a no-op.
+type Entry struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Kind        EntryKind `gorm:"type:varchar(32);not null"`
+	Reference   string    `gorm:"type:varchar(128);not null"`
+	Description string    `gorm:"type:text;not null;default:''"`
+	Currency    string    `gorm:"type:varchar(3);not null"`
+	Postings    []Posting `gorm:"foreignKey:EntryID"`
+	CreatedAt   time.Time
+}
+
+// Posting moves Amount into (positive, debit) or out of (negative, credit)
+// an account.
+type Posting struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntryID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	AccountID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Amount    int64     `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+func (Account) TableName() string { return "ledger_accounts" }
+func (Entry) TableName() string   { return "ledger_entries" }
+func (Posting) TableName() string { return "ledger_postings" }
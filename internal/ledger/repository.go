@@ -0,0 +1,168 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// BalanceFilter selects accounts; zero values are ignored.
+type BalanceFilter struct {
+	Code     string
+	UserID   string
+	LoanID   string
+	Currency string
+}
+
+// AccountBalance is an account with the signed sum of its postings
+// (positive means a net debit).
+type AccountBalance struct {
+	AccountID uuid.UUID
+	Code      string
+	Type      AccountType
+	Currency  string
+	UserID    string
+	LoanID    string
+	Balance   int64
+}
+
+type UnbalancedEntry struct {
+	EntryID   uuid.UUID
+	Kind      EntryKind
+	Reference string
+	Sum       int64
+}
+
+type Repository interface {
+	// Post writes the entry and its postings in one transaction, creating
+	// accounts as needed. It returns false if an entry with the same kind
+	// and reference already exists.
+	Post(ctx context.Context, in EntryInput) (bool, error)
+	Balances(ctx context.Context, f BalanceFilter) ([]AccountBalance, error)
+	UnbalancedEntries(ctx context.Context) ([]UnbalancedEntry, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Post(ctx context.Context, in EntryInput) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entry := Entry{
+			Kind:        in.Kind,
+			Reference:   in.Reference,
+			Description: in.Description,
+			Currency:    in.Currency,
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Postings").Create(&entry)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		postings := make([]Posting, len(in.Lines))
+		for i, l := range in.Lines {
+			acc, err := r.account(tx, l.Account, in.Currency)
+			if err != nil {
+				return err
+			}
+			postings[i] = Posting{EntryID: entry.ID, AccountID: acc.ID, Amount: l.Amount}
+		}
+		if err := tx.Create(&postings).Error; err != nil {
+			return err
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, apperror.NewInternal("posting ledger entry", err)
+	}
+	return created, nil
+}
+
+// account finds or creates the account for ref in the given currency.
+func (r *repository) account(tx *gorm.DB, ref AccountRef, currency string) (*Account, error) {
+	acc := Account{
+		Code:     ref.Code,
+		Type:     ref.Type,
+		Currency: currency,
+		UserID:   ref.UserID,
+		LoanID:   ref.LoanID,
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&acc).Error; err != nil {
+		return nil, err
+	}
+
+	var existing Account
+	err := tx.Where("code = ? AND currency = ?", ref.Code, currency).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("ledger account vanished after upsert")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the first posting may have come from a flow that did not know the user
+	if existing.UserID == "" && ref.UserID != "" {
+		if err := tx.Model(&existing).Update("user_id", ref.UserID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &existing, nil
+}
+
+func (r *repository) Balances(ctx context.Context, f BalanceFilter) ([]AccountBalance, error) {
+	q := r.db.WithContext(ctx).
+		Table("ledger_accounts a").
+		Select("a.id AS account_id, a.code, a.type, a.currency, a.user_id, a.loan_id, COALESCE(SUM(p.amount), 0) AS balance").
+		Joins("LEFT JOIN ledger_postings p ON p.account_id = a.id").
+		Group("a.id").
+		Order("a.currency, a.code")
+
+	if f.Code != "" {
+		q = q.Where("a.code = ?", f.Code)
+	}
+	if f.UserID != "" {
+		q = q.Where("a.user_id = ?", f.UserID)
+	}
+	if f.LoanID != "" {
+		q = q.Where("a.loan_id = ?", f.LoanID)
+	}
+	if f.Currency != "" {
+		q = q.Where("a.currency = ?", f.Currency)
+	}
+
+	var balances []AccountBalance
+	if err := q.Scan(&balances).Error; err != nil {
+		return nil, apperror.NewInternal("computing ledger balances", err)
+	}
+	return balances, nil
+}
+
+func (r *repository) UnbalancedEntries(ctx context.Context) ([]UnbalancedEntry, error) {
+	var entries []UnbalancedEntry
+	err := r.db.WithContext(ctx).
+		Table("ledger_entries e").
+		Select("e.id AS entry_id, e.kind, e.reference, COALESCE(SUM(p.amount), 0) AS sum").
+		Joins("LEFT JOIN ledger_postings p ON p.entry_id = e.id").
+		Group("e.id").
+		Having("COALESCE(SUM(p.amount), 0) <> 0 OR COUNT(p.id) < 2").
+		Scan(&entries).Error
+	if err != nil {
+		return nil, apperror.NewInternal("checking ledger entries", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package ledger
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+type Service interface {
+	// Post validates and writes a journal entry. Posting the same
+	// (kind, reference) twice is a no-op.
+	Post(ctx context.Context, in EntryInput) error
+	Balances(ctx context.Context, f BalanceFilter) ([]AccountBalance, error)
+	TrialBalance(ctx context.Context, currency string) ([]TrialBalance, error)
+	CheckInvariants(ctx context.Context) (*InvariantReport, error)
+}
+
+type service struct {
+	repo   Repository
+	logger *slog.Logger
+}
+
+func NewService(repo Repository, logger *slog.Logger) Service {
+	return &service{repo: repo, logger: logger}
+}
+
+// Post is called after money has already moved, so callers only log its
+// error; the invariant check surfaces anything that went missing.
+func (s *service) Post(ctx context.Context, in EntryInput) error {
+	log := s.logger.With("kind", in.Kind, "reference", in.Reference)
+
+	if err := in.Validate(); err != nil {
+		log.Error("rejected invalid ledger entry", "error", err)
+		return apperror.NewInternal("invalid ledger entry", err)
+	}
+
+	created, err := s.repo.Post(ctx, in)
+	if err != nil {
+		log.Error("failed to post ledger entry", "error", err)
+		return err
+	}
+	if !created {
+		log.Info("ledger entry already posted")
+	}
+	return nil
+}
+
+func (s *service) Balances(ctx context.Context, f BalanceFilter) ([]AccountBalance, error) {
+	return s.repo.Balances(ctx, f)
+}
+
+// TrialBalance is the classic debit/credit listing of every account for one
+// currency (or all currencies when empty).
+type TrialBalance struct {
+	Currency     string
+	Accounts     []AccountBalance
+	TotalDebits  int64
+	TotalCredits int64
+}
+
+func (t TrialBalance) Balanced() bool { return t.TotalDebits == t.TotalCredits }
+
+func (s *service) TrialBalance(ctx context.Context, currency string) ([]TrialBalance, error) {
+	balances, err := s.repo.Balances(ctx, BalanceFilter{Currency: currency})
+	if err != nil {
+		return nil, err
+	}
+
+	byCurrency := map[string]*TrialBalance{}
+	for _, b := range balances {
+		tb, ok := byCurrency[b.Currency]
+		if !ok {
+			tb = &TrialBalance{Currency: b.Currency}
+			byCurrency[b.Currency] = tb
+		}
+		tb.Accounts = append(tb.Accounts, b)
+		if b.Balance > 0 {
+			tb.TotalDebits += b.Balance
+		} else {
+			tb.TotalCredits -= b.Balance
+		}
+	}
+
+	out := make([]TrialBalance, 0, len(byCurrency))
+	for _, tb := range byCurrency {
+		out = append(out, *tb)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out, nil
+}
+
+// InvariantReport lists every violation of the double-entry rules: entries
+// whose postings do not sum to zero, and currencies whose trial balance does
+// not balance.
+type InvariantReport struct {
+	OK                   bool
+	UnbalancedEntries    []UnbalancedEntry
+	UnbalancedCurrencies []string
+}
+
+func (s *service) CheckInvariants(ctx context.Context) (*InvariantReport, error) {
+	entries, err := s.repo.UnbalancedEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trial, err := s.TrialBalance(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &InvariantReport{UnbalancedEntries: entries}
+	for _, tb := range trial {
+		if !tb.Balanced() {
+			report.UnbalancedCurrencies = append(report.UnbalancedCurrencies, tb.Currency)
+		}
+	}
+	report.OK = len(report.UnbalancedEntries) == 0 && len(report.UnbalancedCurrencies) == 0
+
+	if !report.OK {
+		s.logger.Error("ledger invariants violated",
+			"unbalanced_entries", len(report.UnbalancedEntries),
+			"unbalanced_currencies", report.UnbalancedCurrencies,
+		)
+	}
+	return report, nil
+}
@@ -2,17 +2,26 @@ package middleware
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/i18n"
 	"github.com/example/ppo/pkg/response"
 )
 
 // ErrorHandler is a Gin middleware that translates apperror.Kind values into
 // the correct HTTP status code. Handlers only need to call c.Error(err) and
 // return — this middleware takes care of writing the JSON error response.
+//
+// Messages are rendered in the language the Accept-Language header prefers,
+// falling back to English. Clients that accept application/problem+json
+// ahead of application/json get an RFC 7807 problem document instead of the
+// envelope.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
@@ -22,24 +31,118 @@ func ErrorHandler() gin.HandlerFunc {
 		}
 
 		err := c.Errors.Last().Err
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Header("Content-Language", lang)
+		c.Header("Vary", "Accept, Accept-Language")
 
 		var appErr *apperror.Error
 		if !errors.As(err, &appErr) {
-			response.Err(c, http.StatusInternalServerError, "INTERNAL", "unexpected error")
-			return
+			appErr = apperror.NewInternal("unexpected error", err).Localized("error.internal")
 		}
+		appErr = localize(appErr, lang)
 
+		status, body := http.StatusInternalServerError, response.ErrorBody{Code: "INTERNAL", Message: appErr.Message}
 		switch appErr.Kind {
 		case apperror.KindNotFound:
-			response.Err(c, http.StatusNotFound, "NOT_FOUND", appErr.Message)
+			status, body.Code = http.StatusNotFound, "NOT_FOUND"
 		case apperror.KindValidation:
-			response.Err(c, http.StatusBadRequest, "VALIDATION", appErr.Message)
+			status, body.Code, body.Details = http.StatusBadRequest, "VALIDATION", appErr.Fields
+		case apperror.KindInvalidRequest:
+			status, body.Code, body.Details = http.StatusBadRequest, "INVALID_REQUEST", appErr.Fields
 		case apperror.KindConflict:
-			response.Err(c, http.StatusConflict, "CONFLICT", appErr.Message)
+			status, body.Code = http.StatusConflict, "CONFLICT"
+		case apperror.KindDeclined:
+			status, body.Code, body.DeclineCode = http.StatusPaymentRequired, "PAYMENT_DECLINED", appErr.DeclineCode
+		case apperror.KindUnauthorized:
+			c.Header("WWW-Authenticate", "Bearer")
+			status, body.Code = http.StatusUnauthorized, "UNAUTHORIZED"
+		case apperror.KindForbidden:
+			status, body.Code = http.StatusForbidden, "FORBIDDEN"
+		case apperror.KindRateLimited:
+			secs := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(secs))
+			status, body.Code = http.StatusTooManyRequests, "RATE_LIMITED"
 		case apperror.KindUpstream:
-			response.Err(c, http.StatusBadGateway, "UPSTREAM_ERROR", appErr.Message)
-		default:
-			response.Err(c, http.StatusInternalServerError, "INTERNAL", appErr.Message)
+			status, body.Code = http.StatusBadGateway, "UPSTREAM_ERROR"
+		}
+
+		if !wantsProblem(c.GetHeader("Accept")) {
+			response.ErrBody(c, status, body)
+			return
+		}
+		title, _ := i18n.Message(lang, kindKeys[appErr.Kind], nil)
+		response.Problem(c, response.ProblemDetails{
+			Type:        response.ProblemType(body.Code),
+			Title:       title,
+			Status:      status,
+			Detail:      body.Message,
+			Instance:    c.Request.URL.Path,
+			Code:        body.Code,
+			DeclineCode: body.DeclineCode,
+			RequestID:   RequestIDFrom(c.Request.Context()),
+			Errors:      body.Details,
+		})
+	}
+}
+
+// wantsProblem reports whether the Accept header asks for
+// application/problem+json at least as strongly as application/json.
+// Wildcards never select it, so the envelope stays the default.
+func wantsProblem(accept string) bool {
+	var problemQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		media, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(media)) {
+		case response.ProblemContentType:
+			problemQ = max(problemQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+// kindKeys are the catalog entries for errors that carry no key of their own.
+var kindKeys = map[apperror.Kind]string{
+	apperror.KindNotFound:       "error.not_found",
+	apperror.KindValidation:     "error.validation",
+	apperror.KindInvalidRequest: "error.invalid_request",
+	apperror.KindConflict:       "error.conflict",
+	apperror.KindDeclined:       "error.declined",
+	apperror.KindUnauthorized:   "error.unauthorized",
+	apperror.KindForbidden:      "error.forbidden",
+	apperror.KindRateLimited:    "error.rate_limited",
+	apperror.KindUpstream:       "error.upstream",
+	apperror.KindInternal:       "error.internal",
+}
+
+// localize returns a copy of e with its message and field details rendered
+// in lang. An error without a catalog key keeps its English message, or gets
+// its kind's generic message in any other language.
+func localize(e *apperror.Error, lang string) *apperror.Error {
+	out := *e
+	if msg, ok := i18n.Message(lang, e.MessageKey, e.MessageArgs); ok {
+		out.Message = msg
+	} else if lang != i18n.English {
+		out.Message, _ = i18n.Message(lang, kindKeys[e.Kind], nil)
+	}
+
+	if len(e.Fields) > 0 {
+		out.Fields = make([]apperror.FieldError, len(e.Fields))
+		for i, fe := range e.Fields {
+			if msg, ok := i18n.Message(lang, fe.Key, fe.Args); ok {
+				fe.Message = msg
+			}
+			out.Fields[i] = fe
 		}
 	}
+	return &out
 }
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+func newTestRouter(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), ErrorHandler())
+	r.GET("/orders/:id", func(c *gin.Context) { _ = c.Error(err) })
+	return r
+}
+
+func do(r *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders/o-1", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+var invalidAmount = apperror.NewValidation("amount 5 does not match installment amount 10",
+	apperror.Field("amount", "eq", "value", 10)).
+	Localized("installment.amount_mismatch", "amount", 5, "expected", 10)
+
+func TestErrorHandler_Envelope(t *testing.T) {
+	w := do(newTestRouter(invalidAmount), nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("content type = %q", ct)
+	}
+	var body response.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := &response.ErrorBody{
+		Code:    "VALIDATION",
+		Message: "amount 5 does not match installment amount 10",
+		Details: []apperror.FieldError{{Field: "amount", Rule: "eq", Message: "must equal 10"}},
+	}
+	if body.Success || !reflect.DeepEqual(body.Error, want) {
+		t.Errorf("error = %+v, want %+v", body.Error, want)
+	}
+}
+
+func TestErrorHandler_Arabic(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"keyed", invalidAmount, "المبلغ 5 لا يطابق مبلغ القسط 10"},
+		{"unkeyed", apperror.NewNotFound("widget w-1 not found"), "المورد المطلوب غير موجود"},
+		{"plain error", errors.New("boom"), "حدث خطأ غير متوقع"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(newTestRouter(tt.err), map[string]string{"Accept-Language": "ar-SA,en;q=0.5"})
+
+			if got := w.Header().Get("Content-Language"); got != "ar" {
+				t.Errorf("Content-Language = %q, want ar", got)
+			}
+			var body response.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error == nil || body.Error.Message != tt.want {
+				t.Errorf("body = %s, want message %q", w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_Problem(t *testing.T) {
+	w := do(newTestRouter(invalidAmount), map[string]string{
+		"Accept":        "application/problem+json",
+		RequestIDHeader: "req-42",
+	})
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != response.ProblemContentType {
+		t.Errorf("content type = %q, want %q", ct, response.ProblemContentType)
+	}
+	var got response.ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "urn:ppo:problem:validation" || got.Title != "request failed validation" ||
+		got.Status != http.StatusBadRequest || got.Instance != "/orders/o-1" ||
+		got.Detail != "amount 5 does not match installment amount 10" ||
+		got.Code != "VALIDATION" || got.RequestID != "req-42" {
+		t.Errorf("unexpected problem %+v", got)
+	}
+	if len(got.Errors) != 1 || got.Errors[0].Field != "amount" {
+		t.Errorf("errors = %+v", got.Errors)
+	}
+}
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/problem+json, application/json", true},
+		{"application/json, application/problem+json;q=0.9", false},
+		{"application/json;q=0.5, application/problem+json", true},
+		{"application/problem+json;q=0", false},
+		{"Application/Problem+JSON", true},
+	}
+	for _, tt := range tests {
+		if got := wantsProblem(tt.accept); got != tt.want {
+			t.Errorf("wantsProblem(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package order
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/pkg/redact"
+)
 
 type CreateRequest struct {
 	UserID      uuid.UUID         `json:"user_id" binding:"required"`
@@ -17,6 +21,30 @@ type CreateItemInput struct {
 	UnitPrice int64  `json:"unit_price" binding:"required,gt=0"`
 }
 
+type UpdateCardRequest struct {
+	CardToken string `json:"card_token" binding:"required"`
+}
+
+// ForceCancelRequest is the body of an operator's force-cancel.
+type ForceCancelRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CancelResponse reports how a cancellation ended. Status is cancelled while
+// the refund is still pending with the PSP and refunded once it settled.
+type CancelResponse struct {
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewCancelResponse words the reply to a cancellation after its status.
+func NewCancelResponse(status Status) CancelResponse {
+	if status == StatusRefunded {
+		return CancelResponse{Status: status, Message: "order cancelled and refunded"}
+	}
+	return CancelResponse{Status: status, Message: "order cancelled; refund pending"}
+}
+
 type Response struct {
 	ID          uuid.UUID      `json:"id"`
 	UserID      uuid.UUID      `json:"user_id"`
@@ -56,3 +84,26 @@ func ToResponse(o *Order) Response {
 		CreatedAt:   o.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
+
+// CardResponse shows a card on file with its token masked.
+type CardResponse struct {
+	CardToken      string  `json:"card_token"`
+	VerificationID string  `json:"verification_id,omitempty"`
+	Active         bool    `json:"active"`
+	SetAt          string  `json:"set_at"`
+	ReplacedAt     *string `json:"replaced_at,omitempty"`
+}
+
+func ToCardResponse(t *CardToken) CardResponse {
+	resp := CardResponse{
+		CardToken:      redact.CardToken(t.CardToken),
+		VerificationID: t.VerificationID,
+		Active:         t.ReplacedAt == nil,
+		SetAt:          t.SetAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if t.ReplacedAt != nil {
+		replaced := t.ReplacedAt.Format("2006-01-02T15:04:05Z")
+		resp.ReplacedAt = &replaced
+	}
+	return resp
+}
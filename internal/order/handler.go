@@ -1,11 +1,10 @@
 package order
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/example/ppo/pkg/apperror"
 	"github.com/example/ppo/pkg/response"
 )
 
@@ -21,12 +20,22 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	orders := rg.Group("/orders")
 	orders.POST("", h.CreateOrder)
 	orders.POST("/:id/cancel", h.CancelOrder)
+	orders.PUT("/:id/card", h.UpdateCard)
+	orders.GET("/:id/cards", h.CardHistory)
+}
+
+// RegisterAdminRoutes mounts the operator endpoints on the admin API.
+func (h *Handler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/users/:userId/orders", h.ListUserOrders)
+	orders := rg.Group("/orders")
+	orders.GET("/:id", h.GetOrder)
+	orders.POST("/:id/cancel", h.ForceCancel)
 }
 
 func (h *Handler) CreateOrder(c *gin.Context) {
 	var req CreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Err(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		_ = c.Error(apperror.NewBindingError(err))
 		return
 	}
 
@@ -42,14 +51,120 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 func (h *Handler) CancelOrder(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		response.Err(c, http.StatusBadRequest, "INVALID_ID", "invalid order id")
+		_ = c.Error(apperror.NewInvalidRequest("invalid order id", apperror.Field("id", "uuid")).Localized("order.invalid_id"))
+		return
+	}
+
+	status, err := h.svc.Cancel(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, NewCancelResponse(status))
+}
+
+// UpdateCard replaces the card on file. Future auto-charges use the new card
+// straight away; the previous one is kept in the card history.
+func (h *Handler) UpdateCard(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid order id", apperror.Field("id", "uuid")).Localized("order.invalid_id"))
+		return
+	}
+
+	var req UpdateCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	o, err := h.svc.UpdateCard(c.Request.Context(), id, req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(o))
+}
+
+func (h *Handler) CardHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid order id", apperror.Field("id", "uuid")).Localized("order.invalid_id"))
+		return
+	}
+
+	tokens, err := h.svc.CardHistory(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]CardResponse, len(tokens))
+	for i := range tokens {
+		out[i] = ToCardResponse(&tokens[i])
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) ListUserOrders(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid user id", apperror.Field("userId", "uuid")).
+			Localized("user.invalid_id", "user", c.Param("userId")))
+		return
+	}
+
+	orders, err := h.svc.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]Response, len(orders))
+	for i := range orders {
+		out[i] = ToResponse(&orders[i])
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid order id", apperror.Field("id", "uuid")).Localized("order.invalid_id"))
+		return
+	}
+
+	o, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(o))
+}
+
+// ForceCancel cancels any customer's order. Unlike the customer endpoint it
+// requires a reason.
+func (h *Handler) ForceCancel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid order id", apperror.Field("id", "uuid")).Localized("order.invalid_id"))
 		return
 	}
 
-	if err := h.svc.Cancel(c.Request.Context(), id); err != nil {
+	var req ForceCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	status, err := h.svc.ForceCancel(c.Request.Context(), id, req.Reason)
+	if err != nil {
 		_ = c.Error(err)
 		return
 	}
 
-	response.OK(c, gin.H{"message": "order cancelled and refunded"})
+	response.OK(c, NewCancelResponse(status))
 }
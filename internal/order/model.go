@@ -15,6 +15,12 @@ const (
 	StatusRefunded  Status = "refunded"
 )
 
+// Chargeable reports whether installments of an order in this status may
+// still be collected from the customer's card.
+func (s Status) Chargeable() bool {
+	return s == StatusCreated || s == StatusActive
+}
+
 type Order struct {
 	ID          uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	UserID      uuid.UUID   `gorm:"type:uuid;not null;index"`
@@ -22,7 +28,7 @@ type Order struct {
 	Status      Status      `gorm:"type:varchar(20);not null;default:'created'"`
 	TotalAmount int64       `gorm:"not null"`
 	Currency    string      `gorm:"type:varchar(3);not null;default:'SAR'"`
-	CardToken   string      `gorm:"type:varchar(255);not null"`
+	CardToken   string      `gorm:"type:text;not null"`
 	Items       []OrderItem `gorm:"foreignKey:OrderID"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
@@ -37,5 +43,19 @@ type OrderItem struct {
 	CreatedAt time.Time
 }
 
+// CardToken is one card that has been on file for an order. The card in use
+// has no ReplacedAt and matches Order.CardToken. VerificationID is the PSP
+// check made when the card was added; it is empty for the card given at
+// checkout.
+type CardToken struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OrderID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	CardToken      string    `gorm:"type:text;not null"`
+	VerificationID string    `gorm:"type:varchar(128);not null;default:''"`
+	SetAt          time.Time `gorm:"not null"`
+	ReplacedAt     *time.Time
+}
+
 func (Order) TableName() string     { return "orders" }
 func (OrderItem) TableName() string { return "order_items" }
+func (CardToken) TableName() string { return "order_card_tokens" }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/envelope"
 )
 
 type Repository interface {
@@ -16,18 +18,40 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error
 	FindByLoanID(ctx context.Context, loanID string) (*Order, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Order, error)
+	// ReplaceCard makes token the order's card on file and retires the
+	// current one in the card history, in one transaction.
+	ReplaceCard(ctx context.Context, o *Order, token, verificationID string) error
+	ListCardTokens(ctx context.Context, orderID uuid.UUID) ([]CardToken, error)
+	// RotateCardKeys re-encrypts every stored card token that is still in
+	// plaintext or sealed under a retired key, and returns how many it
+	// rewrote.
+	RotateCardKeys(ctx context.Context) (int, error)
 }
 
+// repository stores card tokens sealed with envelope encryption. Callers
+// always see plaintext: tokens are sealed on the way in and opened on the
+// way out.
 type repository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	sealer *envelope.Sealer
 }
 
-func NewRepository(db *gorm.DB) Repository {
-	return &repository{db: db}
+func NewRepository(db *gorm.DB, sealer *envelope.Sealer) Repository {
+	return &repository{db: db, sealer: sealer}
 }
 
 func (r *repository) Create(ctx context.Context, order *Order) error {
-	if err := r.db.WithContext(ctx).Create(order).Error; err != nil {
+	plain := order.CardToken
+	sealed, err := r.sealer.Seal(ctx, plain)
+	if err != nil {
+		return apperror.NewInternal("encrypting card token", err)
+	}
+
+	order.CardToken = sealed
+	err = r.db.WithContext(ctx).Create(order).Error
+	order.CardToken = plain
+	if err != nil {
 		return apperror.NewInternal("creating order", err)
 	}
 	return nil
@@ -37,11 +61,14 @@ func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*Order, error)
 	var o Order
 	err := r.db.WithContext(ctx).Preload("Items").First(&o, "id = ?", id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, apperror.NewNotFound(fmt.Sprintf("order %s not found", id))
+		return nil, apperror.NewNotFound(fmt.Sprintf("order %s not found", id)).Localized("order.not_found", "order", id)
 	}
 	if err != nil {
 		return nil, apperror.NewInternal("fetching order", err)
 	}
+	if err := r.open(ctx, &o.CardToken); err != nil {
+		return nil, err
+	}
 	return &o, nil
 }
 
@@ -51,7 +78,7 @@ func (r *repository) UpdateStatus(ctx context.Context, id uuid.UUID, status Stat
 		return apperror.NewInternal("updating order status", res.Error)
 	}
 	if res.RowsAffected == 0 {
-		return apperror.NewNotFound(fmt.Sprintf("order %s not found", id))
+		return apperror.NewNotFound(fmt.Sprintf("order %s not found", id)).Localized("order.not_found", "order", id)
 	}
 	return nil
 }
@@ -60,10 +87,169 @@ func (r *repository) FindByLoanID(ctx context.Context, loanID string) (*Order, e
 	var o Order
 	err := r.db.WithContext(ctx).Preload("Items").First(&o, "loan_id = ?", loanID).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, apperror.NewNotFound(fmt.Sprintf("order with loan %s not found", loanID))
+		return nil, apperror.NewNotFound(fmt.Sprintf("order with loan %s not found", loanID)).Localized("order.loan_not_found", "loan", loanID)
 	}
 	if err != nil {
 		return nil, apperror.NewInternal("fetching order by loan", err)
 	}
+	if err := r.open(ctx, &o.CardToken); err != nil {
+		return nil, err
+	}
 	return &o, nil
 }
+
+func (r *repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Order, error) {
+	var orders []Order
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		return nil, apperror.NewInternal("listing orders by user", err)
+	}
+	for i := range orders {
+		if err := r.open(ctx, &orders[i].CardToken); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+func (r *repository) ReplaceCard(ctx context.Context, o *Order, token, verificationID string) error {
+	now := time.Now().UTC()
+
+	sealedNew, err := r.sealer.Seal(ctx, token)
+	if err != nil {
+		return apperror.NewInternal("encrypting card token", err)
+	}
+	sealedOld, err := r.sealer.Seal(ctx, o.CardToken)
+	if err != nil {
+		return apperror.NewInternal("encrypting card token", err)
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&CardToken{}).
+			Where("order_id = ? AND replaced_at IS NULL", o.ID).
+			Update("replaced_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		// orders created before card history existed have no row yet for
+		// the card given at checkout
+		if res.RowsAffected == 0 {
+			if err := tx.Create(&CardToken{
+				OrderID:    o.ID,
+				CardToken:  sealedOld,
+				SetAt:      o.CreatedAt,
+				ReplacedAt: &now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&CardToken{
+			OrderID:        o.ID,
+			CardToken:      sealedNew,
+			VerificationID: verificationID,
+			SetAt:          now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&Order{}).Where("id = ?", o.ID).Update("card_token", sealedNew).Error
+	})
+	if err != nil {
+		return apperror.NewInternal("replacing order card", err)
+	}
+
+	o.CardToken = token
+	return nil
+}
+
+func (r *repository) ListCardTokens(ctx context.Context, orderID uuid.UUID) ([]CardToken, error) {
+	var tokens []CardToken
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("set_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, apperror.NewInternal("listing order cards", err)
+	}
+	for i := range tokens {
+		if err := r.open(ctx, &tokens[i].CardToken); err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// rotateBatchSize bounds how many rows RotateCardKeys loads at a time.
+const rotateBatchSize = 500
+
+// sealedRow is the slice of a table RotateCardKeys reads and rewrites.
+type sealedRow struct {
+	ID        uuid.UUID
+	CardToken string
+}
+
+func (r *repository) RotateCardKeys(ctx context.Context) (int, error) {
+	total := 0
+	for _, table := range []string{Order{}.TableName(), CardToken{}.TableName()} {
+		n, err := r.rotateTable(ctx, table)
+		total += n
+		if err != nil {
+			return total, apperror.NewInternal(fmt.Sprintf("rotating card keys in %s", table), err)
+		}
+	}
+	return total, nil
+}
+
+// rotateTable walks the table in primary-key order and reseals each token
+// that needs it. Every row is updated on its own, so an interrupted run can
+// simply be started again.
+func (r *repository) rotateTable(ctx context.Context, table string) (int, error) {
+	db := r.db.WithContext(ctx)
+	rotated := 0
+	var after uuid.UUID
+
+	for {
+		var rows []sealedRow
+		if err := db.Table(table).
+			Select("id", "card_token").
+			Where("id > ?", after).
+			Order("id").
+			Limit(rotateBatchSize).
+			Find(&rows).Error; err != nil {
+			return rotated, err
+		}
+
+		for _, row := range rows {
+			if !r.sealer.NeedsRotation(row.CardToken) {
+				continue
+			}
+			resealed, err := r.sealer.Reseal(ctx, row.CardToken)
+			if err != nil {
+				return rotated, fmt.Errorf("row %s: %w", row.ID, err)
+			}
+			if err := db.Table(table).
+				Where("id = ? AND card_token = ?", row.ID, row.CardToken).
+				Update("card_token", resealed).Error; err != nil {
+				return rotated, fmt.Errorf("row %s: %w", row.ID, err)
+			}
+			rotated++
+		}
+
+		if len(rows) < rotateBatchSize {
+			return rotated, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+func (r *repository) open(ctx context.Context, token *string) error {
+	plain, err := r.sealer.Open(ctx, *token)
+	if err != nil {
+		return apperror.NewInternal("decrypting card token", err)
+	}
+	*token = plain
+	return nil
+}
@@ -7,15 +7,38 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/example/ppo/internal/audit"
+	"github.com/example/ppo/internal/auth"
 	"github.com/example/ppo/internal/client/lms"
 	"github.com/example/ppo/internal/client/product"
 	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/ledger"
+	"github.com/example/ppo/internal/payment"
 	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/redact"
 )
 
 type Service interface {
 	Create(ctx context.Context, req CreateRequest) (*Order, error)
-	Cancel(ctx context.Context, orderID uuid.UUID) error
+	// Cancel returns the order's new status: refunded, or cancelled while
+	// the refund is still pending with the PSP.
+	Cancel(ctx context.Context, orderID uuid.UUID) (Status, error)
+	// MarkRefunded completes a cancellation whose refund settled after the
+	// order was cancelled: only now is the loan marked refunded in LMS and
+	// the stock put back.
+	MarkRefunded(ctx context.Context, orderID uuid.UUID) error
+	// UpdateCard replaces the card future auto-charges use, after the PSP
+	// verifies it with a zero-amount check.
+	UpdateCard(ctx context.Context, orderID uuid.UUID, req UpdateCardRequest) (*Order, error)
+	CardHistory(ctx context.Context, orderID uuid.UUID) ([]CardToken, error)
+
+	// Get, ListByUser and ForceCancel serve operators on the admin API and
+	// are not restricted to the order's owner.
+	Get(ctx context.Context, orderID uuid.UUID) (*Order, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Order, error)
+	// ForceCancel cancels and refunds an order on an operator's behalf. The
+	// reason is kept in the log next to the operator's identity.
+	ForceCancel(ctx context.Context, orderID uuid.UUID, reason string) (Status, error)
 }
 
 type service struct {
@@ -23,6 +46,9 @@ type service struct {
 	lmsClient  lms.Client
 	pspClient  psp.Client
 	prodClient product.Client
+	payments   payment.Service
+	ledger     ledger.Service
+	audit      audit.Service
 	logger     *slog.Logger
 }
 
@@ -31,6 +57,9 @@ func NewService(
 	lmsClient lms.Client,
 	pspClient psp.Client,
 	prodClient product.Client,
+	payments payment.Service,
+	ledgerSvc ledger.Service,
+	auditSvc audit.Service,
 	logger *slog.Logger,
 ) Service {
 	return &service{
@@ -38,11 +67,18 @@ func NewService(
 		lmsClient:  lmsClient,
 		pspClient:  pspClient,
 		prodClient: prodClient,
+		payments:   payments,
+		ledger:     ledgerSvc,
+		audit:      auditSvc,
 		logger:     logger,
 	}
 }
 
 func (s *service) Create(ctx context.Context, req CreateRequest) (*Order, error) {
+	if err := auth.RequireUser(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
 	items := make([]OrderItem, len(req.Items))
 	for i, it := range req.Items {
 		items[i] = OrderItem{
@@ -66,58 +102,302 @@ func (s *service) Create(ctx context.Context, req CreateRequest) (*Order, error)
 		return nil, err
 	}
 
+	_ = s.ledger.Post(ctx, ledger.OrderCreated(o.ID.String(), o.LoanID, o.UserID.String(), o.TotalAmount, o.Currency))
+	_ = s.audit.Record(ctx, audit.Entry{
+		Action:     audit.ActionOrderCreate,
+		EntityType: audit.EntityOrder,
+		EntityID:   o.ID.String(),
+		After:      ToResponse(o),
+	})
+
 	return o, nil
 }
 
 // Cancel orchestrates a full cancellation:
-// 1. Fetch order from DB
-// 2. Call LMS to see how much the user actually paid
-// 3. Refund via PSP if anything was paid
-// 4. Mark loan as refunded in LMS
-// 5. Restock every item via Product Service
-// 6. Update local order status
-func (s *service) Cancel(ctx context.Context, orderID uuid.UUID) error {
+//  1. Fetch order from DB
+//  2. Call LMS to see how much the user actually paid
+//  3. Refund via PSP if anything was paid
+//  4. Mark loan as refunded in LMS and restock every item via Product
+//     Service, once the refund has settled (see closeLoan)
+//  5. Update local order status: refunded, or cancelled while the PSP is
+//     still processing the refund (the PSP webhook finishes it)
+//  6. Close the loan in the ledger
+func (s *service) Cancel(ctx context.Context, orderID uuid.UUID) (Status, error) {
 	o, err := s.repo.GetByID(ctx, orderID)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if err := auth.RequireUser(ctx, o.UserID); err != nil {
+		return "", err
 	}
 
 	if o.Status == StatusCancelled || o.Status == StatusRefunded {
-		return apperror.NewConflict(fmt.Sprintf("order %s is already %s", orderID, o.Status))
+		return "", apperror.NewConflict(fmt.Sprintf("order %s is already %s", orderID, o.Status)).
+			Localized("order.already_closed", "order", orderID, "status", o.Status)
 	}
 
 	loan, err := s.lmsClient.GetLoan(ctx, o.LoanID)
 	if err != nil {
-		return apperror.NewUpstream("fetching loan from LMS", err)
+		return "", apperror.NewUpstream("fetching loan from LMS", err).Localized("upstream.lms")
 	}
 
-	if loan.PaidAmount > 0 {
-		_, err = s.pspClient.Refund(ctx, psp.RefundRequest{
-			OrderID:   orderID.String(),
-			Amount:    loan.PaidAmount,
-			Currency:  o.Currency,
-			CardToken: o.CardToken,
-		})
-		if err != nil {
-			return apperror.NewUpstream("refunding via PSP", err)
+	final, err := s.refund(ctx, o, loan.PaidAmount)
+	if err != nil {
+		return "", err
+	}
+
+	if final == StatusRefunded {
+		if err := s.closeLoan(ctx, o); err != nil {
+			return "", err
 		}
 	}
 
-	if err := s.lmsClient.UpdateLoanStatus(ctx, o.LoanID, "refunded"); err != nil {
+	if err := s.repo.UpdateStatus(ctx, orderID, final); err != nil {
+		return "", err
+	}
+
+	_ = s.ledger.Post(ctx, ledger.LoanCancelled(o.ID.String(), o.LoanID, o.TotalAmount, o.Currency))
+	s.recordStatus(ctx, audit.ActionOrderCancel, o, final)
+
+	return final, nil
+}
+
+func (s *service) MarkRefunded(ctx context.Context, orderID uuid.UUID) error {
+	o, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	switch o.Status {
+	case StatusRefunded:
+		return nil
+	case StatusCancelled:
+		if err := s.closeLoan(ctx, o); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateStatus(ctx, orderID, StatusRefunded); err != nil {
+			return err
+		}
+		s.recordStatus(ctx, audit.ActionOrderRefunded, o, StatusRefunded)
+		return nil
+	default:
+		return apperror.NewConflict(fmt.Sprintf("order %s is %s, not awaiting a refund", orderID, o.Status)).
+			Localized("order.not_awaiting_refund", "order", orderID, "status", o.Status)
+	}
+}
+
+// refund returns what the customer paid and reports the order's status
+// afterwards: refunded, or cancelled while the PSP is still processing the
+// refund. A refund left behind by an earlier attempt is picked up rather
+// than sent again, so retrying a cancellation never refunds twice.
+func (s *service) refund(ctx context.Context, o *Order, amount int64) (Status, error) {
+	prior, err := s.payments.List(ctx, payment.Filter{
+		Type:     payment.TypeRefund,
+		OrderID:  &o.ID,
+		Statuses: []payment.Status{payment.StatusPending, payment.StatusSucceeded},
+		Limit:    1,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(prior) > 0 {
+		if prior[0].Status == payment.StatusSucceeded {
+			return StatusRefunded, nil
+		}
+		return StatusCancelled, nil
+	}
+	if amount <= 0 {
+		return StatusRefunded, nil
+	}
+
+	rec := &payment.Payment{
+		Type:     payment.TypeRefund,
+		Source:   payment.SourceCancellation,
+		Amount:   amount,
+		Currency: o.Currency,
+		LoanID:   o.LoanID,
+		OrderID:  &o.ID,
+	}
+	if err := s.payments.Start(ctx, rec); err != nil {
+		return "", err
+	}
+
+	refundResp, err := s.pspClient.Refund(ctx, psp.RefundRequest{
+		OrderID:   o.ID.String(),
+		Amount:    amount,
+		Currency:  o.Currency,
+		CardToken: o.CardToken,
+	})
+	if err != nil {
+		_ = s.payments.Fail(ctx, rec.ID, err.Error())
+		return "", apperror.NewUpstream("refunding via PSP", err).Localized("upstream.psp")
+	}
+
+	switch refundResp.Status {
+	case psp.RefundStatusRefunded:
+		if err := s.payments.Succeed(ctx, rec.ID, refundResp.RefundID); err != nil {
+			return "", err
+		}
+		return StatusRefunded, nil
+	case psp.RefundStatusPending:
+		// without the reference neither the webhook nor the poller can
+		// find the refund; the payment service has logged it for review
+		if err := s.payments.Submit(ctx, rec.ID, refundResp.RefundID); err != nil {
+			return "", err
+		}
+		return StatusCancelled, nil
+	default:
+		err := fmt.Errorf("refund %s is %s", refundResp.RefundID, refundResp.Status)
+		_ = s.payments.Fail(ctx, rec.ID, err.Error())
+		return "", apperror.NewUpstream("refunding via PSP", err).Localized("upstream.psp")
+	}
+}
+
+// closeLoan marks the loan refunded in LMS and puts the items back in
+// stock. It only runs once the refund has settled: a refund that is still
+// pending may yet fail, and LMS would then show money returned that never
+// was.
+func (s *service) closeLoan(ctx context.Context, o *Order) error {
+	if err := s.lmsClient.UpdateLoanStatus(ctx, o.LoanID, lms.LoanStatusRefunded); err != nil {
 		s.logger.Error("failed to update loan status after refund", "loan_id", o.LoanID, "error", err)
-		return apperror.NewUpstream("updating loan status in LMS", err)
+		return apperror.NewUpstream("updating loan status in LMS", err).Localized("upstream.lms")
 	}
 
 	for _, item := range o.Items {
 		if err := s.prodClient.RestockItem(ctx, item.ProductID, item.Quantity); err != nil {
 			s.logger.Error("failed to restock item", "product_id", item.ProductID, "error", err)
-			return apperror.NewUpstream("restocking inventory", err)
+			return apperror.NewUpstream("restocking inventory", err).Localized("upstream.product")
 		}
 	}
+	return nil
+}
+
+func (s *service) UpdateCard(ctx context.Context, orderID uuid.UUID, req UpdateCardRequest) (*Order, error) {
+	o, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.RequireUser(ctx, o.UserID); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.UpdateStatus(ctx, orderID, StatusRefunded); err != nil {
-		return err
+	if !o.Status.Chargeable() {
+		return nil, apperror.NewConflict(fmt.Sprintf("order %s is %s; its card can no longer be changed", orderID, o.Status)).
+			Localized("order.card_locked", "order", orderID, "status", o.Status)
+	}
+	if req.CardToken == o.CardToken {
+		return o, nil
 	}
 
-	return nil
+	verifyResp, err := s.pspClient.VerifyCard(ctx, psp.VerifyCardRequest{
+		CardToken: req.CardToken,
+		Currency:  o.Currency,
+	})
+	if err != nil {
+		return nil, apperror.NewUpstream("verifying card via PSP", err).Localized("upstream.psp")
+	}
+	switch verifyResp.Status {
+	case psp.VerificationStatusVerified:
+	case psp.VerificationStatusDeclined:
+		return nil, apperror.NewDeclined("card verification declined by issuer", verifyResp.DeclineCode).
+			Localized("card.verification_declined")
+	default:
+		err := fmt.Errorf("unexpected verification status %q", verifyResp.Status)
+		return nil, apperror.NewUpstream("verifying card via PSP", err).Localized("upstream.psp")
+	}
+
+	previous := redact.CardToken(o.CardToken)
+	if err := s.repo.ReplaceCard(ctx, o, req.CardToken, verifyResp.VerificationID); err != nil {
+		return nil, err
+	}
+	_ = s.audit.Record(ctx, audit.Entry{
+		Action:     audit.ActionOrderCardUpdate,
+		EntityType: audit.EntityOrder,
+		EntityID:   o.ID.String(),
+		Before:     map[string]string{"card_token": previous},
+		After: map[string]string{
+			"card_token":      redact.CardToken(o.CardToken),
+			"verification_id": verifyResp.VerificationID,
+		},
+	})
+
+	s.logger.Info("order card replaced",
+		"order_id", orderID,
+		"card_token", redact.CardToken(req.CardToken),
+		"verification_id", verifyResp.VerificationID,
+	)
+	return o, nil
+}
+
+func (s *service) CardHistory(ctx context.Context, orderID uuid.UUID) ([]CardToken, error) {
+	o, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.RequireUser(ctx, o.UserID); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.repo.ListCardTokens(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	// an order whose card was never replaced has no history rows yet
+	if len(tokens) == 0 {
+		tokens = []CardToken{{
+			OrderID:   o.ID,
+			CardToken: o.CardToken,
+			SetAt:     o.CreatedAt,
+		}}
+	}
+	return tokens, nil
+}
+
+func (s *service) Get(ctx context.Context, orderID uuid.UUID) (*Order, error) {
+	return s.repo.GetByID(ctx, orderID)
+}
+
+func (s *service) ListByUser(ctx context.Context, userID uuid.UUID) ([]Order, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) ForceCancel(ctx context.Context, orderID uuid.UUID, reason string) (Status, error) {
+	op, ok := auth.OperatorFrom(ctx)
+	if !ok {
+		return "", apperror.NewForbidden("force-cancel requires an operator").Localized("order.operator_required")
+	}
+
+	status, err := s.Cancel(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("order force-cancelled",
+		"order_id", orderID,
+		"operator", op.Subject,
+		"reason", reason,
+	)
+	// the cancellation itself is recorded by Cancel; this adds the reason
+	_ = s.audit.Record(ctx, audit.Entry{
+		Action:     audit.ActionOrderForceCancel,
+		EntityType: audit.EntityOrder,
+		EntityID:   orderID.String(),
+		After:      map[string]string{"reason": reason},
+	})
+	return status, nil
+}
+
+// recordStatus audits a status change of o, which still holds the old
+// status.
+func (s *service) recordStatus(ctx context.Context, action string, o *Order, status Status) {
+	before := ToResponse(o)
+	after := before
+	after.Status = status
+	_ = s.audit.Record(ctx, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityOrder,
+		EntityID:   o.ID.String(),
+		Before:     before,
+		After:      after,
+	})
 }
@@ -0,0 +1,278 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/example/ppo/internal/client/psp"
+)
+
+// Step names the stage of a collection that failed.
+type Step string
+
+const (
+	StepAuthorize Step = "authorize"
+	StepApply     Step = "apply"
+	StepCapture   Step = "capture"
+)
+
+// ErrPending is the cause of a CollectError when the PSP accepted a request
+// but has not decided it yet. The rows stay in flight until a webhook or the
+// pending-payment poller resolves them: pending rows wait for the
+// authorization, authorized rows for the capture.
+var ErrPending = errors.New("PSP outcome pending")
+
+// ErrActionRequired is the cause of a CollectError when the issuer wants the
+// customer to authenticate (3-D Secure). The rows wait in requires_action
+// until the customer returns and the collection is resumed with Complete.
+var ErrActionRequired = errors.New("customer action required")
+
+// ErrNotClaimed is returned by Complete when the rows were no longer waiting
+// for the customer: another completion of the same charge got to them first.
+var ErrNotClaimed = errors.New("payment is already being completed")
+
+// CollectError is returned by Collect when the PSP or the Apply callback
+// fails. Err is the underlying error, unchanged. RedirectURL is set with
+// ErrActionRequired.
+type CollectError struct {
+	Step        Step
+	Err         error
+	RedirectURL string
+}
+
+func (e *CollectError) Error() string { return fmt.Sprintf("%s: %v", e.Step, e.Err) }
+
+func (e *CollectError) Unwrap() error { return e.Err }
+
+// Collection describes one card charge covering one or more payment rows.
+// The rows are not yet stored; Collect starts them and the card is
+// authorized for the sum of their amounts.
+type Collection struct {
+	Payments  []*Payment
+	Currency  string
+	CardToken string
+
+	// OffSession marks a charge made without the customer present, such as
+	// the auto-charge job. A challenge cannot be answered, so the
+	// authorization is voided instead of waiting for the customer.
+	OffSession bool
+
+	// Apply records the payment downstream (LMS) once the funds are held.
+	// It must leave nothing behind when it returns an error.
+	Apply func(ctx context.Context, transactionID string) error
+
+	// Revert undoes a successful Apply when the capture fails afterwards.
+	// It is optional for flows that have nothing to undo.
+	Revert func(ctx context.Context, transactionID string)
+}
+
+// Collector takes money in three steps: authorize the card, let the caller
+// apply the payment downstream, then capture. If Apply fails the
+// authorization is voided so no money moves; if capture fails Revert runs
+// and the authorization is voided too.
+type Collector struct {
+	pspClient psp.Client
+	payments  Service
+	logger    *slog.Logger
+}
+
+func NewCollector(pspClient psp.Client, payments Service, logger *slog.Logger) *Collector {
+	return &Collector{pspClient: pspClient, payments: payments, logger: logger}
+}
+
+// Collect runs the collection and returns the PSP transaction ID. The ID is
+// also returned alongside a *CollectError whenever the PSP assigned one, so
+// callers can log it or report a pending payment.
+func (c *Collector) Collect(ctx context.Context, col Collection) (string, error) {
+	var total int64
+	for i, p := range col.Payments {
+		if err := c.payments.Start(ctx, p); err != nil {
+			for _, started := range col.Payments[:i] {
+				_ = c.payments.Fail(ctx, started.ID, "aborted before authorization")
+			}
+			return "", err
+		}
+		total += p.Amount
+	}
+
+	authResp, err := c.pspClient.Authorize(ctx, psp.AuthorizeRequest{
+		Amount:     total,
+		Currency:   col.Currency,
+		CardToken:  col.CardToken,
+		OffSession: col.OffSession,
+	})
+	if err != nil {
+		c.fail(ctx, col.Payments, err.Error())
+		return "", &CollectError{Step: StepAuthorize, Err: err}
+	}
+	txnID := authResp.TransactionID
+
+	switch authResp.Status {
+	case psp.ChargeStatusAuthorized:
+		for _, p := range col.Payments {
+			_ = c.payments.Authorize(ctx, p.ID, txnID)
+		}
+	case psp.ChargeStatusPending:
+		for _, p := range col.Payments {
+			_ = c.payments.Submit(ctx, p.ID, txnID)
+		}
+		return txnID, &CollectError{Step: StepAuthorize, Err: ErrPending}
+	case psp.ChargeStatusRequiresAction:
+		if col.OffSession {
+			c.void(ctx, col.Payments, txnID, "customer authentication required")
+			return txnID, &CollectError{Step: StepAuthorize, Err: ErrActionRequired}
+		}
+		for _, p := range col.Payments {
+			_ = c.payments.RequireAction(ctx, p.ID, txnID, authResp.RedirectURL)
+		}
+		return txnID, &CollectError{Step: StepAuthorize, Err: ErrActionRequired, RedirectURL: authResp.RedirectURL}
+	case psp.ChargeStatusDeclined:
+		declined := &psp.DeclineError{Code: authResp.DeclineCode}
+		c.fail(ctx, col.Payments, declined.Error())
+		return txnID, &CollectError{Step: StepAuthorize, Err: declined}
+	default:
+		err := fmt.Errorf("unexpected authorization status %q", authResp.Status)
+		c.fail(ctx, col.Payments, err.Error())
+		return txnID, &CollectError{Step: StepAuthorize, Err: err}
+	}
+
+	return c.finish(ctx, col, txnID, total)
+}
+
+// Complete resumes a collection that stopped with ErrActionRequired once the
+// customer is back from the challenge. col.Payments are the stored
+// requires_action rows sharing one PSP reference; Apply and Revert play the
+// same roles as in Collect. The rows are claimed (moved to pending) before
+// the PSP is asked, so concurrent completions cannot both apply the payment;
+// the losers get ErrNotClaimed. If the customer has not finished the
+// challenge yet the rows go back to requires_action.
+func (c *Collector) Complete(ctx context.Context, col Collection) (string, error) {
+	txnID := col.Payments[0].PSPReference
+	var total int64
+	for _, p := range col.Payments {
+		total += p.Amount
+	}
+
+	claimed, err := c.payments.Claim(ctx, txnID, StatusRequiresAction, StatusPending)
+	if err != nil {
+		return txnID, err
+	}
+	if !claimed {
+		return txnID, ErrNotClaimed
+	}
+
+	txn, err := c.pspClient.GetTransaction(ctx, txnID)
+	if err != nil {
+		c.awaitAction(ctx, col.Payments, txnID)
+		return txnID, &CollectError{Step: StepAuthorize, Err: err}
+	}
+
+	switch psp.ChargeStatus(txn.Status) {
+	case psp.ChargeStatusAuthorized:
+		// the webhook or the poller may have seen the authorization first
+		// and be finishing the rows already
+		taken, err := c.payments.Claim(ctx, txnID, StatusPending, StatusAuthorized)
+		if err != nil || !taken {
+			return txnID, &CollectError{Step: StepAuthorize, Err: ErrPending}
+		}
+	case psp.ChargeStatusRequiresAction:
+		c.awaitAction(ctx, col.Payments, txnID)
+		return txnID, &CollectError{Step: StepAuthorize, Err: ErrActionRequired, RedirectURL: col.Payments[0].ActionURL}
+	case psp.ChargeStatusPending:
+		// the rows are already pending; the webhook or the poller finishes
+		// them once the PSP decides
+		return txnID, &CollectError{Step: StepAuthorize, Err: ErrPending}
+	case psp.ChargeStatusDeclined:
+		declined := &psp.DeclineError{Code: txn.DeclineCode}
+		c.fail(ctx, col.Payments, declined.Error())
+		return txnID, &CollectError{Step: StepAuthorize, Err: declined}
+	default:
+		err := fmt.Errorf("unexpected authorization status %q", txn.Status)
+		c.fail(ctx, col.Payments, err.Error())
+		return txnID, &CollectError{Step: StepAuthorize, Err: err}
+	}
+
+	return c.finish(ctx, col, txnID, total)
+}
+
+// Finish applies and captures a collection whose rows were claimed as
+// authorized after the request that started them had ended, such as rows the
+// PSP authorized by webhook once they were reported pending.
+func (c *Collector) Finish(ctx context.Context, col Collection) (string, error) {
+	var total int64
+	for _, p := range col.Payments {
+		total += p.Amount
+	}
+	return c.finish(ctx, col, col.Payments[0].PSPReference, total)
+}
+
+// awaitAction puts claimed rows back in requires_action so the customer can
+// try the completion again.
+func (c *Collector) awaitAction(ctx context.Context, rows []*Payment, transactionID string) {
+	for _, p := range rows {
+		_ = c.payments.RequireAction(ctx, p.ID, transactionID, p.ActionURL)
+	}
+}
+
+// finish applies an authorized collection downstream and captures it.
+func (c *Collector) finish(ctx context.Context, col Collection, txnID string, total int64) (string, error) {
+	if err := col.Apply(ctx, txnID); err != nil {
+		c.void(ctx, col.Payments, txnID, fmt.Sprintf("not applied: %v", err))
+		return txnID, &CollectError{Step: StepApply, Err: err}
+	}
+
+	captureResp, err := c.pspClient.Capture(ctx, psp.CaptureRequest{
+		TransactionID: txnID,
+		Amount:        total,
+	})
+	if err == nil {
+		switch captureResp.Status {
+		case psp.ChargeStatusCaptured:
+			for _, p := range col.Payments {
+				_ = c.payments.Succeed(ctx, p.ID, txnID)
+			}
+			return txnID, nil
+		case psp.ChargeStatusPending:
+			// the rows stay authorized; the payment is already applied
+			c.logger.Info("PSP capture pending", "transaction_id", txnID, "amount", total)
+			return txnID, &CollectError{Step: StepCapture, Err: ErrPending}
+		default:
+			err = fmt.Errorf("capture %s", captureResp.Status)
+		}
+	}
+
+	c.logger.Error("PSP capture failed after payment was applied — reverting",
+		"transaction_id", txnID,
+		"amount", total,
+		"error", err,
+	)
+	if col.Revert != nil {
+		col.Revert(ctx, txnID)
+	}
+	c.void(ctx, col.Payments, txnID, fmt.Sprintf("capture failed: %v", err))
+	return txnID, &CollectError{Step: StepCapture, Err: err}
+}
+
+func (c *Collector) fail(ctx context.Context, rows []*Payment, reason string) {
+	for _, p := range rows {
+		_ = c.payments.Fail(ctx, p.ID, reason)
+	}
+}
+
+// void releases the authorization. If the PSP refuses, the hold lapses on
+// its own, so the rows are marked failed rather than voided.
+func (c *Collector) void(ctx context.Context, rows []*Payment, transactionID, reason string) {
+	if _, err := c.pspClient.Void(ctx, psp.VoidRequest{TransactionID: transactionID}); err != nil {
+		c.logger.Error("failed to void PSP authorization — hold remains until it expires",
+			"transaction_id", transactionID,
+			"error", err,
+		)
+		c.fail(ctx, rows, reason)
+		return
+	}
+	for _, p := range rows {
+		_ = c.payments.Void(ctx, p.ID, reason)
+	}
+}
@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListQuery is bound from the query string of GET /payments. From and To are
+// RFC 3339 timestamps.
+type ListQuery struct {
+	Type          Type   `form:"type" binding:"omitempty,oneof=charge refund"`
+	Status        Status `form:"status"`
+	LoanID        string `form:"loan_id"`
+	InstallmentID string `form:"installment_id"`
+	OrderID       string `form:"order_id" binding:"omitempty,uuid"`
+	PSPReference  string `form:"psp_reference"`
+	From          string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To            string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit         int    `form:"limit" binding:"omitempty,gt=0"`
+	Offset        int    `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// ToFilter converts the already-validated query into a repository filter.
+func (q ListQuery) ToFilter() Filter {
+	f := Filter{
+		Type:          q.Type,
+		Status:        q.Status,
+		LoanID:        q.LoanID,
+		InstallmentID: q.InstallmentID,
+		PSPReference:  q.PSPReference,
+		Limit:         q.Limit,
+		Offset:        q.Offset,
+	}
+	if id, err := uuid.Parse(q.OrderID); err == nil {
+		f.OrderID = &id
+	}
+	f.From, _ = time.Parse(time.RFC3339, q.From)
+	f.To, _ = time.Parse(time.RFC3339, q.To)
+	return f
+}
+
+type Response struct {
+	ID            uuid.UUID  `json:"id"`
+	Type          Type       `json:"type"`
+	Status        Status     `json:"status"`
+	Source        Source     `json:"source"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	PSPReference  string     `json:"psp_reference,omitempty"`
+	LoanID        string     `json:"loan_id,omitempty"`
+	InstallmentID string     `json:"installment_id,omitempty"`
+	OrderID       *uuid.UUID `json:"order_id,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	ActionURL     string     `json:"action_url,omitempty"`
+	CreatedAt     string     `json:"created_at"`
+	UpdatedAt     string     `json:"updated_at"`
+}
+
+func ToResponse(p *Payment) Response {
+	return Response{
+		ID:            p.ID,
+		Type:          p.Type,
+		Status:        p.Status,
+		Source:        p.Source,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		PSPReference:  p.PSPReference,
+		LoanID:        p.LoanID,
+		InstallmentID: p.InstallmentID,
+		OrderID:       p.OrderID,
+		FailureReason: p.FailureReason,
+		ActionURL:     p.ActionURL,
+		CreatedAt:     p.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:     p.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	payments := rg.Group("/payments")
+	payments.GET("", h.ListPayments)
+	payments.GET("/:id", h.GetPayment)
+}
+
+func (h *Handler) ListPayments(c *gin.Context) {
+	var q ListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	payments, err := h.svc.List(c.Request.Context(), q.ToFilter())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]Response, len(payments))
+	for i := range payments {
+		out[i] = ToResponse(&payments[i])
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) GetPayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid payment id", apperror.Field("id", "uuid")).Localized("payment.invalid_id"))
+		return
+	}
+
+	p, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(p))
+}
@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	TypeCharge Type = "charge"
+	TypeRefund Type = "refund"
+)
+
+type Status string
+
+const (
+	StatusPending        Status = "pending"
+	StatusRequiresAction Status = "requires_action"
+	StatusAuthorized     Status = "authorized"
+	StatusSucceeded      Status = "succeeded"
+	StatusFailed         Status = "failed"
+	StatusVoided         Status = "voided"
+)
+
+// InFlightStatuses are the statuses whose PSP outcome is still open: the
+// card may yet be charged for the row.
+var InFlightStatuses = []Status{StatusPending, StatusRequiresAction, StatusAuthorized}
+
+func (s Status) InFlight() bool {
+	return slices.Contains(InFlightStatuses, s)
+}
+
+// Source records which flow moved the money.
+type Source string
+
+const (
+	SourceInstallment  Source = "installment"
+	SourceBatch        Source = "batch"
+	SourcePayoff       Source = "payoff"
+	SourceAutoCharge   Source = "auto_charge"
+	SourceCancellation Source = "cancellation"
+)
+
+// Payment is one money movement through the PSP. A batch checkout produces
+// one row per allocated installment, all sharing the same PSPReference.
+// ActionURL is where the customer completes a 3-D Secure challenge while the
+// row is in requires_action.
+type Payment struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Type          Type       `gorm:"type:varchar(20);not null"`
+	Status        Status     `gorm:"type:varchar(20);not null;default:'pending'"`
+	Source        Source     `gorm:"type:varchar(32);not null"`
+	Amount        int64      `gorm:"not null"`
+	Currency      string     `gorm:"type:varchar(3);not null"`
+	PSPReference  string     `gorm:"column:psp_reference;type:varchar(128);not null;default:''"`
+	LoanID        string     `gorm:"type:varchar(64);not null;default:''"`
+	InstallmentID string     `gorm:"type:varchar(64);not null;default:''"`
+	OrderID       *uuid.UUID `gorm:"type:uuid"`
+	FailureReason string     `gorm:"type:text;not null;default:''"`
+	ActionURL     string     `gorm:"type:text;not null;default:''"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (Payment) TableName() string { return "payments" }
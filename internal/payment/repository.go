@@ -0,0 +1,155 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Filter narrows a payment listing. Zero values are ignored.
+type Filter struct {
+	Type          Type
+	Status        Status
+	Statuses      []Status
+	LoanID        string
+	InstallmentID string
+	OrderID       *uuid.UUID
+	PSPReference  string
+	From          time.Time
+	To            time.Time
+	Limit         int
+	Offset        int
+}
+
+type Repository interface {
+	Create(ctx context.Context, p *Payment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Payment, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status Status, pspReference, failureReason string) error
+	RequireAction(ctx context.Context, id uuid.UUID, pspReference, actionURL string) error
+	// Transition moves every row sharing pspReference from one status to
+	// another in a single statement and reports how many moved.
+	Transition(ctx context.Context, pspReference string, from, to Status) (int64, error)
+	List(ctx context.Context, f Filter) ([]Payment, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, p *Payment) error {
+	if err := r.db.WithContext(ctx).Create(p).Error; err != nil {
+		return apperror.NewInternal("creating payment", err)
+	}
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*Payment, error) {
+	var p Payment
+	err := r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NewNotFound(fmt.Sprintf("payment %s not found", id)).Localized("payment.not_found", "payment", id)
+	}
+	if err != nil {
+		return nil, apperror.NewInternal("fetching payment", err)
+	}
+	return &p, nil
+}
+
+// UpdateStatus moves a payment to its new status. An empty pspReference
+// keeps the one already stored.
+func (r *repository) UpdateStatus(ctx context.Context, id uuid.UUID, status Status, pspReference, failureReason string) error {
+	updates := map[string]any{
+		"status":         status,
+		"failure_reason": failureReason,
+	}
+	if pspReference != "" {
+		updates["psp_reference"] = pspReference
+	}
+
+	res := r.db.WithContext(ctx).Model(&Payment{}).Where("id = ?", id).Updates(updates)
+	if res.Error != nil {
+		return apperror.NewInternal("updating payment status", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return apperror.NewNotFound(fmt.Sprintf("payment %s not found", id)).Localized("payment.not_found", "payment", id)
+	}
+	return nil
+}
+
+func (r *repository) RequireAction(ctx context.Context, id uuid.UUID, pspReference, actionURL string) error {
+	res := r.db.WithContext(ctx).Model(&Payment{}).Where("id = ?", id).Updates(map[string]any{
+		"status":        StatusRequiresAction,
+		"psp_reference": pspReference,
+		"action_url":    actionURL,
+	})
+	if res.Error != nil {
+		return apperror.NewInternal("updating payment status", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return apperror.NewNotFound(fmt.Sprintf("payment %s not found", id)).Localized("payment.not_found", "payment", id)
+	}
+	return nil
+}
+
+func (r *repository) Transition(ctx context.Context, pspReference string, from, to Status) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&Payment{}).
+		Where("psp_reference = ? AND status = ?", pspReference, from).
+		Update("status", to)
+	if res.Error != nil {
+		return 0, apperror.NewInternal("updating payment status", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+func (r *repository) List(ctx context.Context, f Filter) ([]Payment, error) {
+	q := r.db.WithContext(ctx).Model(&Payment{})
+	if f.Type != "" {
+		q = q.Where("type = ?", f.Type)
+	}
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if len(f.Statuses) > 0 {
+		q = q.Where("status IN ?", f.Statuses)
+	}
+	if f.LoanID != "" {
+		q = q.Where("loan_id = ?", f.LoanID)
+	}
+	if f.InstallmentID != "" {
+		q = q.Where("installment_id = ?", f.InstallmentID)
+	}
+	if f.OrderID != nil {
+		q = q.Where("order_id = ?", *f.OrderID)
+	}
+	if f.PSPReference != "" {
+		q = q.Where("psp_reference = ?", f.PSPReference)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("created_at < ?", f.To)
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+
+	var payments []Payment
+	if err := q.Order("created_at DESC").Find(&payments).Error; err != nil {
+		return nil, apperror.NewInternal("listing payments", err)
+	}
+	return payments, nil
+}
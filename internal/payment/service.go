@@ -0,0 +1,170 @@
+package payment
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/ledger"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// Service is the local record of every money movement. Flows that charge or
+// refund call Start before talking to the PSP and Succeed/Fail afterwards, so
+// a row stuck in pending always points at a PSP call whose outcome is unknown.
+// A PSP request that is accepted but settles later is recorded with Submit
+// and finished from the PSP webhook. Charges taken through a Collector pass
+// through authorized and end either succeeded (captured) or voided. Succeeded
+// payments are also posted to the double-entry ledger.
+type Service interface {
+	Start(ctx context.Context, p *Payment) error
+	Submit(ctx context.Context, id uuid.UUID, pspReference string) error
+	RequireAction(ctx context.Context, id uuid.UUID, pspReference, actionURL string) error
+	Authorize(ctx context.Context, id uuid.UUID, pspReference string) error
+	// Claim moves the rows sharing pspReference from one status to another
+	// and reports false if none was still in from. It is a compare-and-set:
+	// of several callers racing to finish the same charge, only one wins.
+	Claim(ctx context.Context, pspReference string, from, to Status) (bool, error)
+	Succeed(ctx context.Context, id uuid.UUID, pspReference string) error
+	Fail(ctx context.Context, id uuid.UUID, reason string) error
+	Void(ctx context.Context, id uuid.UUID, reason string) error
+	Get(ctx context.Context, id uuid.UUID) (*Payment, error)
+	List(ctx context.Context, f Filter) ([]Payment, error)
+}
+
+type service struct {
+	repo   Repository
+	ledger ledger.Service
+	logger *slog.Logger
+}
+
+func NewService(repo Repository, ledgerSvc ledger.Service, logger *slog.Logger) Service {
+	return &service{repo: repo, ledger: ledgerSvc, logger: logger}
+}
+
+func (s *service) Start(ctx context.Context, p *Payment) error {
+	p.Status = StatusPending
+	return s.repo.Create(ctx, p)
+}
+
+func (s *service) Submit(ctx context.Context, id uuid.UUID, pspReference string) error {
+	if err := s.repo.UpdateStatus(ctx, id, StatusPending, pspReference, ""); err != nil {
+		s.logger.Error("failed to store PSP reference of pending payment",
+			"payment_id", id,
+			"psp_reference", pspReference,
+			"error", err,
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *service) RequireAction(
+	ctx context.Context,
+	id uuid.UUID,
+	pspReference, actionURL string,
+) error {
+	if err := s.repo.RequireAction(ctx, id, pspReference, actionURL); err != nil {
+		s.logger.Error("failed to mark payment as requiring action",
+			"payment_id", id,
+			"psp_reference", pspReference,
+			"error", err,
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Authorize(ctx context.Context, id uuid.UUID, pspReference string) error {
+	if err := s.repo.UpdateStatus(ctx, id, StatusAuthorized, pspReference, ""); err != nil {
+		s.logger.Error("failed to mark payment authorized",
+			"payment_id", id,
+			"psp_reference", pspReference,
+			"error", err,
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Claim(ctx context.Context, pspReference string, from, to Status) (bool, error) {
+	n, err := s.repo.Transition(ctx, pspReference, from, to)
+	if err != nil {
+		s.logger.Error("failed to claim payments",
+			"psp_reference", pspReference,
+			"from", from,
+			"to", to,
+			"error", err,
+		)
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *service) Succeed(ctx context.Context, id uuid.UUID, pspReference string) error {
+	if err := s.repo.UpdateStatus(ctx, id, StatusSucceeded, pspReference, ""); err != nil {
+		s.logger.Error("failed to mark payment succeeded",
+			"payment_id", id,
+			"psp_reference", pspReference,
+			"error", err,
+		)
+		return err
+	}
+
+	s.postToLedger(ctx, id)
+	return nil
+}
+
+// postToLedger books a succeeded payment against its loan. The payment
+// itself is already final, so ledger problems are only logged.
+func (s *service) postToLedger(ctx context.Context, id uuid.UUID) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to load payment for ledger posting", "payment_id", id, "error", err)
+		return
+	}
+	if p.LoanID == "" {
+		s.logger.Warn("payment has no loan, skipping ledger posting", "payment_id", id)
+		return
+	}
+
+	switch p.Type {
+	case TypeCharge:
+		_ = s.ledger.Post(ctx, ledger.PaymentReceived(p.ID.String(), p.LoanID, p.Amount, p.Currency))
+	case TypeRefund:
+		_ = s.ledger.Post(ctx, ledger.RefundIssued(p.ID.String(), p.LoanID, p.Amount, p.Currency))
+	}
+}
+
+func (s *service) Fail(ctx context.Context, id uuid.UUID, reason string) error {
+	if err := s.repo.UpdateStatus(ctx, id, StatusFailed, "", reason); err != nil {
+		s.logger.Error("failed to mark payment failed", "payment_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Void(ctx context.Context, id uuid.UUID, reason string) error {
+	if err := s.repo.UpdateStatus(ctx, id, StatusVoided, "", reason); err != nil {
+		s.logger.Error("failed to mark payment voided", "payment_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *service) Get(ctx context.Context, id uuid.UUID) (*Payment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, f Filter) ([]Payment, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultListLimit
+	}
+	f.Limit = min(f.Limit, maxListLimit)
+	return s.repo.List(ctx, f)
+}
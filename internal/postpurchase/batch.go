@@ -0,0 +1,91 @@
+package postpurchase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/audit"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// PayInstallments pays several installments with a single card charge.
+// Allocation is all-or-nothing: every installment is validated before the
+// card is authorized, and if LMS rejects any allocation the ones already
+// recorded are reversed and the authorization is voided, so nothing is
+// captured. The caller must own every loan in the batch.
+func (s *service) PayInstallments(ctx context.Context, req PayInstallmentsRequest) (*PayInstallmentsResponse, error) {
+	owned := make(map[string]bool)
+	for _, in := range req.Installments {
+		if owned[in.LoanID] {
+			continue
+		}
+		if _, err := s.ownedLoan(ctx, in.LoanID); err != nil {
+			return nil, err
+		}
+		owned[in.LoanID] = true
+	}
+
+	seen := make(map[string]bool, len(req.Installments))
+	allocations := make([]Allocation, 0, len(req.Installments))
+	var total int64
+
+	for i, in := range req.Installments {
+		path := fmt.Sprintf("installments[%d].", i)
+		if seen[in.InstallmentID] {
+			return nil, apperror.NewValidation(fmt.Sprintf("installment %s is listed more than once", in.InstallmentID),
+				apperror.Field(path+"installment_id", "unique")).
+				Localized("installment.duplicate", "installment", in.InstallmentID)
+		}
+		seen[in.InstallmentID] = true
+
+		if _, err := s.validateInstallment(ctx, path, in.LoanID, in.InstallmentID, in.Amount, req.Currency); err != nil {
+			return nil, err
+		}
+
+		allocations = append(allocations, Allocation{
+			LoanID:        in.LoanID,
+			InstallmentID: in.InstallmentID,
+			Amount:        in.Amount,
+		})
+		total += in.Amount
+	}
+
+	// one ledger row per allocation, all sharing the PSP transaction ID
+	recs := make([]*payment.Payment, len(allocations))
+	for i, a := range allocations {
+		recs[i] = &payment.Payment{
+			Type:          payment.TypeCharge,
+			Source:        payment.SourceBatch,
+			Amount:        a.Amount,
+			Currency:      req.Currency,
+			LoanID:        a.LoanID,
+			InstallmentID: a.InstallmentID,
+		}
+	}
+
+	col := s.installmentCollection(recs)
+	col.Currency = req.Currency
+	col.CardToken = req.CardToken
+
+	txnID, err := s.collector.Collect(ctx, col)
+	out, err := collectOutcome(err, "paid", "recording payment in LMS")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PayInstallmentsResponse{
+		ReceiptID:     uuid.NewString(),
+		PaymentID:     recs[0].ID.String(),
+		TransactionID: txnID,
+		TotalAmount:   total,
+		Currency:      req.Currency,
+		Status:        out.status,
+		RedirectURL:   out.redirectURL,
+		Allocations:   allocations,
+	}
+	s.recordPayment(ctx, audit.ActionPaymentPay, resp.PaymentID, resp)
+	return resp, nil
+}
@@ -0,0 +1,152 @@
+package postpurchase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/ledger"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// installmentCollection builds the collection for one or more installment
+// rows: each row is recorded in LMS as an installment payment. The caller
+// fills in the card details.
+func (s *service) installmentCollection(rows []*payment.Payment) payment.Collection {
+	return payment.Collection{
+		Payments: rows,
+		Apply: func(ctx context.Context, transactionID string) error {
+			return s.recordInstallments(ctx, rows, transactionID)
+		},
+		Revert: func(ctx context.Context, transactionID string) {
+			s.reverseInstallments(ctx, rows, transactionID)
+		},
+	}
+}
+
+// payoffCollection builds the collection for a loan payoff: LMS settles the
+// whole loan and the early-payoff discount is booked in the ledger.
+func (s *service) payoffCollection(row *payment.Payment, discount int64) payment.Collection {
+	return payment.Collection{
+		Payments: []*payment.Payment{row},
+		Currency: row.Currency,
+		Apply: func(ctx context.Context, transactionID string) error {
+			if err := s.lmsClient.SettleLoan(ctx, lms.SettleLoanRequest{
+				LoanID:        row.LoanID,
+				Amount:        row.Amount,
+				Discount:      discount,
+				TransactionID: transactionID,
+			}); err != nil {
+				return err
+			}
+			if discount > 0 {
+				_ = s.ledger.Post(ctx, ledger.PayoffDiscount(row.LoanID, discount, row.Currency))
+			}
+			return nil
+		},
+		// LMS has no way to undo a settlement
+		Revert: func(_ context.Context, transactionID string) {
+			s.logger.Error("loan settled in LMS but PSP capture failed — needs reconciliation",
+				"loan_id", row.LoanID,
+				"transaction_id", transactionID,
+				"amount", row.Amount,
+			)
+		},
+	}
+}
+
+// recordInstallments records every row in LMS. If one is rejected, the ones
+// already recorded are reversed so the authorization can be voided cleanly.
+func (s *service) recordInstallments(ctx context.Context, rows []*payment.Payment, transactionID string) error {
+	for i, row := range rows {
+		if err := s.lmsClient.RecordPayment(ctx, lms.RecordPaymentRequest{
+			LoanID:        row.LoanID,
+			InstallmentID: row.InstallmentID,
+			Amount:        row.Amount,
+			TransactionID: transactionID,
+		}); err != nil {
+			s.logger.Error("LMS rejected installment payment — rolling back",
+				"loan_id", row.LoanID,
+				"installment_id", row.InstallmentID,
+				"transaction_id", transactionID,
+				"error", err,
+			)
+			s.reverseInstallments(ctx, rows[:i], transactionID)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) reverseInstallments(ctx context.Context, rows []*payment.Payment, transactionID string) {
+	for _, row := range rows {
+		s.reversePayment(ctx, row.LoanID, row.InstallmentID, transactionID)
+	}
+}
+
+// reversePayment undoes an LMS installment payment whose charge was never
+// captured. A failure leaves LMS ahead of the money and is only logged.
+func (s *service) reversePayment(ctx context.Context, loanID, installmentID, transactionID string) {
+	if err := s.lmsClient.ReversePayment(ctx, lms.ReversePaymentRequest{
+		LoanID:        loanID,
+		InstallmentID: installmentID,
+		TransactionID: transactionID,
+	}); err != nil {
+		s.logger.Error("failed to reverse LMS payment — needs reconciliation",
+			"loan_id", loanID,
+			"installment_id", installmentID,
+			"transaction_id", transactionID,
+			"error", err,
+		)
+	}
+}
+
+// outcome is what the client is told about a collection that did not fail.
+type outcome struct {
+	status      string
+	redirectURL string
+}
+
+// collectOutcome turns the result of a collection into the status reported
+// to the client. A PSP outcome that is still pending is not an error: the
+// webhook or the pending-payment poller finishes it. Neither is a 3-D Secure
+// challenge: the client sends the customer to the redirect URL and then
+// calls the completion endpoint.
+func collectOutcome(err error, done, applyMessage string) (outcome, error) {
+	if err == nil {
+		return outcome{status: done}, nil
+	}
+	if errors.Is(err, payment.ErrPending) {
+		return outcome{status: "pending"}, nil
+	}
+	var ce *payment.CollectError
+	if errors.As(err, &ce) && errors.Is(ce.Err, payment.ErrActionRequired) {
+		return outcome{status: "requires_action", redirectURL: ce.RedirectURL}, nil
+	}
+	return outcome{}, collectError(err, applyMessage)
+}
+
+// collectError maps a failed collection onto the API error for the step
+// that failed. Errors from before authorization are already typed.
+func collectError(err error, applyMessage string) error {
+	var ce *payment.CollectError
+	if !errors.As(err, &ce) {
+		return err
+	}
+
+	var declined *psp.DeclineError
+	if errors.As(ce.Err, &declined) {
+		return apperror.NewDeclined("card declined by issuer", declined.Code).Localized("card.declined")
+	}
+
+	switch ce.Step {
+	case payment.StepAuthorize:
+		return apperror.NewUpstream("authorizing via PSP", ce.Err).Localized("upstream.psp")
+	case payment.StepApply:
+		return apperror.NewUpstream(applyMessage, ce.Err).Localized("upstream.lms")
+	default:
+		return apperror.NewUpstream("capturing via PSP", ce.Err).Localized("upstream.psp")
+	}
+}
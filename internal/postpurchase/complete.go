@@ -0,0 +1,116 @@
+package postpurchase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/audit"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// CompletePayment resumes a charge that stopped in requires_action. Every row
+// sharing the PSP transaction (a whole batch) is finished together: once the
+// PSP confirms the authorization the payment is recorded in LMS and
+// captured, exactly as if no challenge had been asked for. Only the owner of
+// the loan may complete its payment.
+func (s *service) CompletePayment(ctx context.Context, paymentID uuid.UUID) (*CompletePaymentResponse, error) {
+	rec, err := s.payments.Get(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	loan, err := s.ownedLoan(ctx, rec.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Type != payment.TypeCharge || rec.Status != payment.StatusRequiresAction {
+		return nil, apperror.NewConflict(fmt.Sprintf("payment %s is %s, not awaiting customer action", paymentID, rec.Status)).
+			Localized("payment.not_awaiting_action", "payment", paymentID, "status", rec.Status)
+	}
+
+	done, applyMessage := "paid", "recording payment in LMS"
+	var col payment.Collection
+
+	if rec.Source == payment.SourcePayoff {
+		// LMS rejects the settlement, and the authorization is voided, if
+		// the balance moved while the customer was authenticating
+		discount := min(max(loan.EarlyPayoffDiscount, 0), loan.Outstanding())
+		col = s.payoffCollection(rec, discount)
+		done, applyMessage = "settled", "settling loan in LMS"
+	} else {
+		siblings, err := s.payments.List(ctx, payment.Filter{
+			Type:         payment.TypeCharge,
+			Status:       payment.StatusRequiresAction,
+			PSPReference: rec.PSPReference,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]*payment.Payment, len(siblings))
+		for i := range siblings {
+			rows[i] = &siblings[i]
+		}
+		col = s.installmentCollection(rows)
+	}
+
+	txnID, err := s.collector.Complete(ctx, col)
+	if errors.Is(err, payment.ErrNotClaimed) {
+		return nil, apperror.NewConflict(fmt.Sprintf("payment %s is already being completed", paymentID)).
+			Localized("payment.completing", "payment", paymentID)
+	}
+	out, err := collectOutcome(err, done, applyMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CompletePaymentResponse{
+		PaymentID:     paymentID.String(),
+		TransactionID: txnID,
+		Status:        out.status,
+		RedirectURL:   out.redirectURL,
+	}
+	s.recordPayment(ctx, audit.ActionPaymentComplete, resp.PaymentID, resp)
+	return resp, nil
+}
+
+// FinishPending finishes rows that were reported pending when the PSP had
+// not decided yet. They share one PSP reference and are claimed before
+// anything happens, so a completion request or a second delivery racing for
+// the same rows leaves them alone. A rejection from LMS voids the
+// authorization, as it does when the PSP answers straight away.
+func (s *service) FinishPending(ctx context.Context, rows []payment.Payment) error {
+	ptrs := make([]*payment.Payment, len(rows))
+	for i := range rows {
+		ptrs[i] = &rows[i]
+	}
+	col := s.installmentCollection(ptrs)
+	if rows[0].Source == payment.SourcePayoff {
+		loan, err := s.lmsClient.GetLoan(ctx, rows[0].LoanID)
+		if err != nil {
+			return apperror.NewUpstream("fetching loan from LMS", err).Localized("upstream.lms")
+		}
+		col = s.payoffCollection(ptrs[0], min(max(loan.EarlyPayoffDiscount, 0), loan.Outstanding()))
+	}
+
+	txnID := rows[0].PSPReference
+	claimed, err := s.payments.Claim(ctx, txnID, payment.StatusPending, payment.StatusAuthorized)
+	if err != nil || !claimed {
+		return err
+	}
+
+	_, err = s.collector.Finish(ctx, col)
+	if errors.Is(err, payment.ErrPending) {
+		// captured later, by webhook like any other pending capture
+		return nil
+	}
+	var ce *payment.CollectError
+	if errors.As(err, &ce) {
+		// the collector has already voided the authorization
+		s.logger.Warn("late authorization could not be applied", "transaction_id", txnID, "error", err)
+		return nil
+	}
+	return err
+}
@@ -8,7 +8,65 @@ type PayInstallmentRequest struct {
 	CardToken     string `json:"card_token" binding:"required"`
 }
 
+// PayInstallmentResponse reports status "paid", "pending" while the PSP
+// decides, or "requires_action" with a RedirectURL where the customer
+// passes a 3-D Secure challenge before calling the completion endpoint.
 type PayInstallmentResponse struct {
+	PaymentID     string `json:"payment_id"`
 	TransactionID string `json:"transaction_id"`
 	Status        string `json:"status"`
+	RedirectURL   string `json:"redirect_url,omitempty"`
+}
+
+type PayInstallmentsRequest struct {
+	Installments []InstallmentPaymentInput `json:"installments" binding:"required,min=1,dive"`
+	Currency     string                    `json:"currency" binding:"required,len=3"`
+	CardToken    string                    `json:"card_token" binding:"required"`
+}
+
+type InstallmentPaymentInput struct {
+	LoanID        string `json:"loan_id" binding:"required"`
+	InstallmentID string `json:"installment_id" binding:"required"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+}
+
+// PayInstallmentsResponse is the single receipt for a multi-installment
+// checkout: one card charge allocated across every installment.
+type PayInstallmentsResponse struct {
+	ReceiptID     string       `json:"receipt_id"`
+	PaymentID     string       `json:"payment_id"`
+	TransactionID string       `json:"transaction_id"`
+	TotalAmount   int64        `json:"total_amount"`
+	Currency      string       `json:"currency"`
+	Status        string       `json:"status"`
+	RedirectURL   string       `json:"redirect_url,omitempty"`
+	Allocations   []Allocation `json:"allocations"`
+}
+
+type Allocation struct {
+	LoanID        string `json:"loan_id"`
+	InstallmentID string `json:"installment_id"`
+	Amount        int64  `json:"amount"`
+}
+
+type PayoffRequest struct {
+	Currency  string `json:"currency" binding:"required,len=3"`
+	CardToken string `json:"card_token" binding:"required"`
+}
+
+type PayoffResponse struct {
+	PaymentID     string `json:"payment_id"`
+	LoanID        string `json:"loan_id"`
+	TransactionID string `json:"transaction_id"`
+	AmountCharged int64  `json:"amount_charged"`
+	Discount      int64  `json:"discount"`
+	Status        string `json:"status"`
+	RedirectURL   string `json:"redirect_url,omitempty"`
+}
+
+type CompletePaymentResponse struct {
+	PaymentID     string `json:"payment_id"`
+	TransactionID string `json:"transaction_id"`
+	Status        string `json:"status"`
+	RedirectURL   string `json:"redirect_url,omitempty"`
 }
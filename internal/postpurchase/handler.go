@@ -1,10 +1,10 @@
 package postpurchase
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/example/ppo/pkg/apperror"
 	"github.com/example/ppo/pkg/response"
 )
 
@@ -19,12 +19,16 @@ func NewHandler(svc Service) *Handler {
 func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/users/:userId/installments", h.GetInstallments)
 	rg.POST("/installments/pay", h.PayInstallment)
+	rg.POST("/installments/pay-batch", h.PayInstallments)
+	rg.POST("/loans/:loanId/payoff", h.PayoffLoan)
+	rg.POST("/payments/:paymentId/complete", h.CompletePayment)
 }
 
 func (h *Handler) GetInstallments(c *gin.Context) {
 	userID := c.Param("userId")
 	if userID == "" {
-		response.Err(c, http.StatusBadRequest, "INVALID_REQUEST", "user_id is required")
+		_ = c.Error(apperror.NewInvalidRequest("user_id is required", apperror.Field("userId", "required")).
+			Localized("user.id_required"))
 		return
 	}
 
@@ -40,7 +44,7 @@ func (h *Handler) GetInstallments(c *gin.Context) {
 func (h *Handler) PayInstallment(c *gin.Context) {
 	var req PayInstallmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Err(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		_ = c.Error(apperror.NewBindingError(err))
 		return
 	}
 
@@ -52,3 +56,54 @@ func (h *Handler) PayInstallment(c *gin.Context) {
 
 	response.OK(c, resp)
 }
+
+func (h *Handler) PayInstallments(c *gin.Context) {
+	var req PayInstallmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	resp, err := h.svc.PayInstallments(c.Request.Context(), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, resp)
+}
+
+func (h *Handler) PayoffLoan(c *gin.Context) {
+	var req PayoffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	resp, err := h.svc.PayoffLoan(c.Request.Context(), c.Param("loanId"), req)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, resp)
+}
+
+// CompletePayment is called once the customer is back from the 3-D Secure
+// redirect returned with status "requires_action".
+func (h *Handler) CompletePayment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("paymentId"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid payment id", apperror.Field("paymentId", "uuid")).
+			Localized("payment.invalid_id"))
+		return
+	}
+
+	resp, err := h.svc.CompletePayment(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, resp)
+}
@@ -2,70 +2,198 @@ package postpurchase
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/audit"
+	"github.com/example/ppo/internal/auth"
 	"github.com/example/ppo/internal/client/lms"
 	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/ledger"
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/payment"
 	"github.com/example/ppo/pkg/apperror"
 )
 
 type Service interface {
 	GetInstallments(ctx context.Context, userID string) ([]lms.Installment, error)
 	PayInstallment(ctx context.Context, req PayInstallmentRequest) (*PayInstallmentResponse, error)
+	PayInstallments(ctx context.Context, req PayInstallmentsRequest) (*PayInstallmentsResponse, error)
+	PayoffLoan(ctx context.Context, loanID string, req PayoffRequest) (*PayoffResponse, error)
+	// CompletePayment finishes a payment that waited for the customer to pass
+	// a 3-D Secure challenge.
+	CompletePayment(ctx context.Context, paymentID uuid.UUID) (*CompletePaymentResponse, error)
+	// FinishPending records in LMS and captures charge rows that were left
+	// pending and have since been authorized. It is used by the PSP webhook.
+	FinishPending(ctx context.Context, rows []payment.Payment) error
 }
 
 type service struct {
 	lmsClient lms.Client
+	orders    order.Repository
 	pspClient psp.Client
+	payments  payment.Service
+	collector *payment.Collector
+	ledger    ledger.Service
+	audit     audit.Service
 	logger    *slog.Logger
 }
 
-func NewService(lmsClient lms.Client, pspClient psp.Client, logger *slog.Logger) Service {
+func NewService(
+	lmsClient lms.Client,
+	orders order.Repository,
+	pspClient psp.Client,
+	payments payment.Service,
+	ledgerSvc ledger.Service,
+	auditSvc audit.Service,
+	logger *slog.Logger,
+) Service {
 	return &service{
 		lmsClient: lmsClient,
+		orders:    orders,
 		pspClient: pspClient,
+		payments:  payments,
+		collector: payment.NewCollector(pspClient, payments, logger),
+		ledger:    ledgerSvc,
+		audit:     auditSvc,
 		logger:    logger,
 	}
 }
 
 func (s *service) GetInstallments(ctx context.Context, userID string) ([]lms.Installment, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperror.NewValidation(fmt.Sprintf("invalid user id %q", userID),
+			apperror.Field("userId", "uuid")).
+			Localized("user.invalid_id", "user", userID)
+	}
+	if err := auth.RequireUser(ctx, id); err != nil {
+		return nil, err
+	}
+
 	installments, err := s.lmsClient.GetInstallments(ctx, userID)
 	if err != nil {
-		return nil, apperror.NewUpstream("fetching installments from LMS", err)
+		return nil, apperror.NewUpstream("fetching installments from LMS", err).Localized("upstream.lms")
 	}
 	return installments, nil
 }
 
-// PayInstallment charges the user's card then records the payment in LMS.
-// If the PSP charge succeeds but LMS fails, we log the orphaned transaction
-// for manual reconciliation (a real system would use an outbox/saga).
+// PayInstallment validates the installment against LMS, authorizes the
+// user's card, records the payment in LMS and only then captures. If LMS
+// rejects the payment the authorization is voided; if the capture fails the
+// LMS payment is reversed. Only the loan's owner may pay.
 func (s *service) PayInstallment(ctx context.Context, req PayInstallmentRequest) (*PayInstallmentResponse, error) {
-	chargeResp, err := s.pspClient.Charge(ctx, psp.ChargeRequest{
-		Amount:    req.Amount,
-		Currency:  req.Currency,
-		CardToken: req.CardToken,
-	})
-	if err != nil {
-		return nil, apperror.NewUpstream("charging via PSP", err)
+	if _, err := s.ownedLoan(ctx, req.LoanID); err != nil {
+		return nil, err
+	}
+	if _, err := s.validateInstallment(ctx, "", req.LoanID, req.InstallmentID, req.Amount, req.Currency); err != nil {
+		return nil, err
 	}
 
-	if err := s.lmsClient.RecordPayment(ctx, lms.RecordPaymentRequest{
+	rec := &payment.Payment{
+		Type:          payment.TypeCharge,
+		Source:        payment.SourceInstallment,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
 		LoanID:        req.LoanID,
 		InstallmentID: req.InstallmentID,
-		Amount:        req.Amount,
-		TransactionID: chargeResp.TransactionID,
-	}); err != nil {
-		s.logger.Error("PSP charge succeeded but LMS recording failed — needs reconciliation",
-			"loan_id", req.LoanID,
-			"installment_id", req.InstallmentID,
-			"transaction_id", chargeResp.TransactionID,
-			"error", err,
-		)
-		return nil, apperror.NewUpstream("recording payment in LMS", err)
-	}
-
-	return &PayInstallmentResponse{
-		TransactionID: chargeResp.TransactionID,
-		Status:        "paid",
-	}, nil
+	}
+	col := s.installmentCollection([]*payment.Payment{rec})
+	col.Currency = req.Currency
+	col.CardToken = req.CardToken
+
+	txnID, err := s.collector.Collect(ctx, col)
+	out, err := collectOutcome(err, "paid", "recording payment in LMS")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PayInstallmentResponse{
+		PaymentID:     rec.ID.String(),
+		TransactionID: txnID,
+		Status:        out.status,
+		RedirectURL:   out.redirectURL,
+	}
+	s.recordPayment(ctx, audit.ActionPaymentPay, resp.PaymentID, resp)
+	return resp, nil
+}
+
+// PayoffLoan settles the whole remaining balance of a loan in one charge.
+// The amount is computed from LMS (outstanding balance minus any early-payoff
+// discount) rather than trusted from the client. The card is authorized
+// first; LMS then settles the loan, marking every remaining installment
+// paid, and the authorization is captured. Only the loan's owner may pay it
+// off.
+func (s *service) PayoffLoan(ctx context.Context, loanID string, req PayoffRequest) (*PayoffResponse, error) {
+	loan, err := s.ownedLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.Status != lms.LoanStatusActive {
+		return nil, apperror.NewConflict(fmt.Sprintf("loan %s is %s", loanID, loan.Status)).
+			Localized("loan.not_payable", "loan", loanID, "status", loan.Status)
+	}
+
+	outstanding := loan.Outstanding()
+	discount := min(max(loan.EarlyPayoffDiscount, 0), outstanding)
+	amount := outstanding - discount
+	if amount <= 0 {
+		return nil, apperror.NewConflict(fmt.Sprintf("loan %s has no outstanding balance", loanID)).
+			Localized("loan.nothing_outstanding", "loan", loanID)
+	}
+
+	// LMS has no currency of its own: the loan is booked in the currency
+	// of the order that opened it
+	o, err := s.orders.FindByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(req.Currency, o.Currency) {
+		return nil, apperror.NewValidation(fmt.Sprintf("currency %s does not match loan currency %s", req.Currency, o.Currency),
+			apperror.Field("currency", "eq", "value", o.Currency)).
+			Localized("loan.currency_mismatch", "currency", req.Currency, "expected", o.Currency)
+	}
+
+	rec := &payment.Payment{
+		Type:     payment.TypeCharge,
+		Source:   payment.SourcePayoff,
+		Amount:   amount,
+		Currency: o.Currency,
+		LoanID:   loanID,
+	}
+	col := s.payoffCollection(rec, discount)
+	col.CardToken = req.CardToken
+
+	txnID, err := s.collector.Collect(ctx, col)
+	out, err := collectOutcome(err, "settled", "settling loan in LMS")
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PayoffResponse{
+		PaymentID:     rec.ID.String(),
+		LoanID:        loanID,
+		TransactionID: txnID,
+		AmountCharged: amount,
+		Discount:      discount,
+		Status:        out.status,
+		RedirectURL:   out.redirectURL,
+	}
+	s.recordPayment(ctx, audit.ActionPaymentPayoff, resp.PaymentID, resp)
+	return resp, nil
+}
+
+// recordPayment audits a payment the customer made, whatever its outcome so
+// far (paid, pending or awaiting 3-D Secure).
+func (s *service) recordPayment(ctx context.Context, action, paymentID string, resp any) {
+	_ = s.audit.Record(ctx, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityPayment,
+		EntityID:   paymentID,
+		After:      resp,
+	})
 }
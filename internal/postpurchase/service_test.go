@@ -0,0 +1,113 @@
+package postpurchase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// memOrders finds orders by loan; nothing else is needed before a payment
+// starts.
+type memOrders struct {
+	order.Repository
+	byLoan map[string]*order.Order
+}
+
+func (r *memOrders) FindByLoanID(_ context.Context, loanID string) (*order.Order, error) {
+	o, ok := r.byLoan[loanID]
+	if !ok {
+		return nil, apperror.NewNotFound("order not found")
+	}
+	return o, nil
+}
+
+// newTestService wires the service to the fake LMS and one SAR order for
+// loan-001. Every case here is rejected before the PSP or the payment ledger
+// is reached, so those stay nil.
+func newTestService() (*service, lms.Client) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lmsClient := lms.NewFake(logger)
+	orders := &memOrders{byLoan: map[string]*order.Order{
+		"loan-001": {ID: uuid.New(), LoanID: "loan-001", Currency: "SAR"},
+	}}
+	return NewService(lmsClient, orders, nil, nil, nil, nil, logger).(*service), lmsClient
+}
+
+// asUser authenticates ctx as the customer userID.
+func asUser(userID uuid.UUID) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{UserID: userID})
+}
+
+// asOwner authenticates ctx as the fake LMS's owner of loanID.
+func asOwner(t *testing.T, lmsClient lms.Client, loanID string) context.Context {
+	t.Helper()
+	loan, err := lmsClient.GetLoan(context.Background(), loanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return asUser(uuid.MustParse(loan.UserID))
+}
+
+func wantKind(t *testing.T, err error, kind apperror.Kind) *apperror.Error {
+	t.Helper()
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Kind != kind {
+		t.Fatalf("expected error kind %d, got %v", kind, err)
+	}
+	return appErr
+}
+
+func TestPayoffLoan_ForbidsOtherUsersLoan(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.PayoffLoan(asUser(uuid.New()), "loan-001", PayoffRequest{Currency: "SAR", CardToken: "tok_visa_4242"})
+	wantKind(t, err, apperror.KindForbidden)
+}
+
+func TestPayoffLoan_RejectsCurrencyMismatch(t *testing.T) {
+	svc, lmsClient := newTestService()
+
+	_, err := svc.PayoffLoan(asOwner(t, lmsClient, "loan-001"), "loan-001", PayoffRequest{Currency: "USD", CardToken: "tok_visa_4242"})
+	appErr := wantKind(t, err, apperror.KindValidation)
+	if len(appErr.Fields) != 1 || appErr.Fields[0].Field != "currency" || appErr.Fields[0].Rule != "eq" {
+		t.Errorf("unexpected field details %+v", appErr.Fields)
+	}
+}
+
+func TestPayInstallment_ForbidsOtherUsersLoan(t *testing.T) {
+	svc, _ := newTestService()
+
+	_, err := svc.PayInstallment(asUser(uuid.New()), PayInstallmentRequest{
+		LoanID:        "loan-001",
+		InstallmentID: "inst-002",
+		Amount:        15000,
+		Currency:      "SAR",
+		CardToken:     "tok_visa_4242",
+	})
+	wantKind(t, err, apperror.KindForbidden)
+}
+
+// the owner of loan-001 may not slip loan-003, which belongs to someone
+// else, into the same batch
+func TestPayInstallments_ForbidsOtherUsersLoan(t *testing.T) {
+	svc, lmsClient := newTestService()
+
+	_, err := svc.PayInstallments(asOwner(t, lmsClient, "loan-001"), PayInstallmentsRequest{
+		Installments: []InstallmentPaymentInput{
+			{LoanID: "loan-001", InstallmentID: "inst-002", Amount: 15000},
+			{LoanID: "loan-003", InstallmentID: "inst-007", Amount: 12000},
+		},
+		Currency:  "SAR",
+		CardToken: "tok_visa_4242",
+	})
+	wantKind(t, err, apperror.KindForbidden)
+}
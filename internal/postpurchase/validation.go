@@ -0,0 +1,71 @@
+package postpurchase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// ownedLoan fetches a loan and fails with a forbidden error unless the
+// authenticated customer owns it, so nobody pays towards, or learns the
+// balance of, someone else's loan.
+func (s *service) ownedLoan(ctx context.Context, loanID string) (*lms.Loan, error) {
+	loan, err := s.lmsClient.GetLoan(ctx, loanID)
+	if errors.Is(err, lms.ErrNotFound) {
+		return nil, apperror.NewNotFound(fmt.Sprintf("loan %s not found", loanID)).Localized("loan.not_found", "loan", loanID)
+	}
+	if err != nil {
+		return nil, apperror.NewUpstream("fetching loan from LMS", err).Localized("upstream.lms")
+	}
+	// a loan whose owner cannot be parsed belongs to nobody
+	owner, _ := uuid.Parse(loan.UserID)
+	if err := auth.RequireUser(ctx, owner); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// validateInstallment checks a payment against LMS before any money moves:
+// the installment must exist, belong to the loan, still be unpaid, and the
+// amount and currency must match what LMS expects. path prefixes the
+// installment's fields in the errors, e.g. "installments[2]." in a batch.
+func (s *service) validateInstallment(ctx context.Context, path, loanID, installmentID string, amount int64, currency string) (*lms.Installment, error) {
+	inst, err := s.lmsClient.GetInstallment(ctx, installmentID)
+	if errors.Is(err, lms.ErrNotFound) {
+		return nil, apperror.NewValidation(fmt.Sprintf("installment %s does not exist", installmentID),
+			apperror.Field(path+"installment_id", "exists")).
+			Localized("installment.not_found", "installment", installmentID)
+	}
+	if err != nil {
+		return nil, apperror.NewUpstream("fetching installment from LMS", err).Localized("upstream.lms")
+	}
+
+	if inst.LoanID != loanID {
+		return nil, apperror.NewValidation(fmt.Sprintf("installment %s does not belong to loan %s", installmentID, loanID),
+			apperror.Field(path+"loan_id", "owner")).
+			Localized("installment.wrong_loan", "installment", installmentID, "loan", loanID)
+	}
+	if inst.Status == lms.InstallmentStatusPaid {
+		return nil, apperror.NewConflict(fmt.Sprintf("installment %s is already paid", installmentID)).
+			Localized("installment.already_paid", "installment", installmentID)
+	}
+	if amount != inst.Amount {
+		return nil, apperror.NewValidation(fmt.Sprintf("amount %d does not match installment amount %d", amount, inst.Amount),
+			apperror.Field(path+"amount", "eq", "value", inst.Amount)).
+			Localized("installment.amount_mismatch", "amount", amount, "expected", inst.Amount)
+	}
+	if inst.Currency != "" && !strings.EqualFold(currency, inst.Currency) {
+		return nil, apperror.NewValidation(fmt.Sprintf("currency %s does not match installment currency %s", currency, inst.Currency),
+			apperror.Field("currency", "eq", "value", inst.Currency)).
+			Localized("installment.currency_mismatch", "currency", currency, "expected", inst.Currency)
+	}
+
+	return inst, nil
+}
@@ -0,0 +1,68 @@
+package pspwebhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+// maxBodySize bounds a webhook delivery; events are a few hundred bytes.
+const maxBodySize = 64 << 10
+
+const (
+	headerSignature = "X-PSP-Signature"
+	headerTimestamp = "X-PSP-Timestamp"
+	headerNonce     = "X-PSP-Nonce"
+)
+
+type Handler struct {
+	svc      Service
+	verifier *Verifier
+}
+
+func NewHandler(svc Service, verifier *Verifier) *Handler {
+	return &Handler{svc: svc, verifier: verifier}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/psp", h.Receive)
+}
+
+// Receive verifies the signature over the raw body before decoding it, so
+// nothing unauthenticated reaches the service.
+func (h *Handler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("could not read request body").Localized("request.body_unreadable"))
+		return
+	}
+
+	nonce := c.GetHeader(headerNonce)
+	if err := h.verifier.Verify(c.GetHeader(headerSignature), c.GetHeader(headerTimestamp), nonce, body); err != nil {
+		msg, key := "invalid webhook signature", "webhook.invalid_signature"
+		if errors.Is(err, ErrStaleTimestamp) {
+			msg, key = "webhook timestamp is too old or in the future", "webhook.stale_timestamp"
+		}
+		_ = c.Error(apperror.NewUnauthorized(msg).Localized(key))
+		return
+	}
+
+	var evt Event
+	if err := binding.JSON.BindBody(body, &evt); err != nil {
+		_ = c.Error(apperror.NewBindingError(err))
+		return
+	}
+
+	if err := h.svc.Receive(c.Request.Context(), nonce, evt); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, gin.H{"received": true})
+}
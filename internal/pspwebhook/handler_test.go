@@ -0,0 +1,67 @@
+package pspwebhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	mw "github.com/example/ppo/internal/middleware"
+	"github.com/example/ppo/pkg/response"
+)
+
+// a rejected delivery goes through the shared error handler, so it honours
+// the problem+json opt-in like every other route.
+func TestReceive_RejectsAsProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(mw.ErrorHandler())
+	NewHandler(nil, NewVerifier("whsec-test", 5*time.Minute)).RegisterRoutes(r.Group("/webhooks"))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/psp", strings.NewReader(`{"id":"evt-1"}`))
+	req.Header.Set("Accept", response.ProblemContentType)
+	req.Header.Set(headerSignature, "not-hex")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != response.ProblemContentType {
+		t.Errorf("content type = %q, want %q", ct, response.ProblemContentType)
+	}
+	var p response.ProblemDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusUnauthorized || p.Detail != "invalid webhook signature" || p.Instance != "/webhooks/psp" {
+		t.Errorf("unexpected problem %+v", p)
+	}
+}
+
+func TestReceive_RejectsInRequestedLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(mw.ErrorHandler())
+	NewHandler(nil, NewVerifier("whsec-test", 5*time.Minute)).RegisterRoutes(r.Group("/webhooks"))
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/psp", strings.NewReader(`{"id":"evt-1"}`))
+	req.Header.Set("Accept-Language", "ar")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Language"); got != "ar" {
+		t.Errorf("Content-Language = %q, want ar", got)
+	}
+	var body response.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error == nil || body.Error.Message != "توقيع الإشعار غير صالح" {
+		t.Errorf("unexpected body %s", w.Body)
+	}
+}
@@ -0,0 +1,39 @@
+package pspwebhook
+
+import "time"
+
+type EventType string
+
+const (
+	EventCharge EventType = "charge"
+	EventRefund EventType = "refund"
+)
+
+// Event is the body the PSP posts when a charge or refund changes status.
+// Reference is the transaction ID for charges and the refund ID for refunds,
+// matching payment.Payment.PSPReference. Status holds a psp.ChargeStatus or
+// psp.RefundStatus according to Type. Amount is what the event covers in
+// minor units and must match the payments it settles; zero means the PSP
+// did not say.
+type Event struct {
+	ID         string    `json:"id" binding:"required"`
+	Type       EventType `json:"type" binding:"required,oneof=charge refund"`
+	Reference  string    `json:"reference" binding:"required"`
+	Status     string    `json:"status" binding:"required"`
+	Amount     int64     `json:"amount"`
+	Reason     string    `json:"reason"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Receipt is the stored trace of an accepted webhook. Its nonce is the
+// primary key, so a replayed delivery cannot be stored twice.
+type Receipt struct {
+	Nonce      string    `gorm:"type:varchar(128);primaryKey"`
+	EventID    string    `gorm:"type:varchar(128);not null"`
+	EventType  EventType `gorm:"type:varchar(20);not null"`
+	Reference  string    `gorm:"type:varchar(128);not null"`
+	Status     string    `gorm:"type:varchar(32);not null"`
+	ReceivedAt time.Time `gorm:"not null"`
+}
+
+func (Receipt) TableName() string { return "psp_webhook_events" }
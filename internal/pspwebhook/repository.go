@@ -0,0 +1,42 @@
+package pspwebhook
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+type Repository interface {
+	// SaveReceipt stores the receipt and reports false if its nonce was
+	// already used.
+	SaveReceipt(ctx context.Context, r *Receipt) (bool, error)
+	// DeleteReceipt frees a nonce whose event could not be applied, so the
+	// PSP's retry is accepted.
+	DeleteReceipt(ctx context.Context, nonce string) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) SaveReceipt(ctx context.Context, rec *Receipt) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(rec)
+	if res.Error != nil {
+		return false, apperror.NewInternal("storing webhook receipt", res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *repository) DeleteReceipt(ctx context.Context, nonce string) error {
+	if err := r.db.WithContext(ctx).Where("nonce = ?", nonce).Delete(&Receipt{}).Error; err != nil {
+		return apperror.NewInternal("deleting webhook receipt", err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package pspwebhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/internal/postpurchase"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+type Service interface {
+	// Receive stores a verified delivery, rejecting a reused nonce, and
+	// applies the event. A delivery that fails to apply gives its nonce back
+	// so the PSP can retry it.
+	Receive(ctx context.Context, nonce string, evt Event) error
+	// Apply moves the in-flight payment rows for the event's reference to
+	// the reported outcome and runs any follow-ups. It is idempotent and is
+	// also used by the pending-payment poller.
+	Apply(ctx context.Context, evt Event) error
+}
+
+type service struct {
+	repo      Repository
+	payments  payment.Service
+	orders    order.Service
+	charges   postpurchase.Service
+	lmsClient lms.Client
+	pspClient psp.Client
+	logger    *slog.Logger
+}
+
+func NewService(
+	repo Repository,
+	payments payment.Service,
+	orders order.Service,
+	charges postpurchase.Service,
+	lmsClient lms.Client,
+	pspClient psp.Client,
+	logger *slog.Logger,
+) Service {
+	return &service{
+		repo:      repo,
+		payments:  payments,
+		orders:    orders,
+		charges:   charges,
+		lmsClient: lmsClient,
+		pspClient: pspClient,
+		logger:    logger,
+	}
+}
+
+func (s *service) Receive(ctx context.Context, nonce string, evt Event) error {
+	fresh, err := s.repo.SaveReceipt(ctx, &Receipt{
+		Nonce:      nonce,
+		EventID:    evt.ID,
+		EventType:  evt.Type,
+		Reference:  evt.Reference,
+		Status:     evt.Status,
+		ReceivedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		s.logger.Warn("rejecting replayed PSP webhook", "nonce", nonce, "event_id", evt.ID)
+		return apperror.NewConflict(fmt.Sprintf("webhook nonce %s was already used", nonce)).
+			Localized("webhook.nonce_reused", "nonce", nonce)
+	}
+
+	if err := s.Apply(ctx, evt); err != nil {
+		// Apply is idempotent, so the retry may safely redo what part of
+		// this attempt already did
+		if delErr := s.repo.DeleteReceipt(context.WithoutCancel(ctx), nonce); delErr != nil {
+			s.logger.Error("failed to release webhook nonce", "nonce", nonce, "event_id", evt.ID, "error", delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) Apply(ctx context.Context, evt Event) error {
+	log := s.logger.With("event_id", evt.ID, "type", evt.Type, "reference", evt.Reference, "status", evt.Status)
+
+	rows, err := s.payments.List(ctx, payment.Filter{
+		Type:         payment.Type(evt.Type),
+		PSPReference: evt.Reference,
+		Statuses:     payment.InFlightStatuses,
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		// either already final or unknown; reconciliation reports the latter
+		log.Info("PSP event has no in-flight payment to update")
+		return nil
+	}
+
+	var total int64
+	for _, row := range rows {
+		total += row.Amount
+	}
+	if evt.Amount != 0 && evt.Amount != total {
+		// a partial capture or refund must not settle the full amount;
+		// the rows stay in flight and reconciliation reports the gap
+		log.Error("PSP event amount does not match its payments — skipping",
+			"event_amount", evt.Amount,
+			"payments_amount", total,
+		)
+		return nil
+	}
+
+	if evt.Type == EventRefund {
+		return s.applyRefund(ctx, rows, psp.RefundStatus(evt.Status), evt.Reason)
+	}
+	return s.applyCharge(ctx, rows, psp.ChargeStatus(evt.Status), evt.Reason)
+}
+
+// applyCharge resolves charge rows. Rows still pending never reached LMS
+// because their authorization was undecided when the request ended; once
+// authorized they are recorded in LMS and captured, unless nobody wants the
+// money any more. Rows that are authorized were recorded in LMS and wait for
+// the capture. Rows in requires_action wait for the customer to call the
+// completion endpoint, so an authorization for them is left alone.
+func (s *service) applyCharge(ctx context.Context, rows []payment.Payment, status psp.ChargeStatus, reason string) error {
+	switch status {
+	case psp.ChargeStatusCaptured:
+		// a pending row captured by the PSP directly is missing in LMS;
+		// reconciliation re-records it
+		for _, row := range rows {
+			if err := s.payments.Succeed(ctx, row.ID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case psp.ChargeStatusAuthorized:
+		var late []payment.Payment
+		for _, row := range rows {
+			if row.Status == payment.StatusPending {
+				late = append(late, row)
+			}
+		}
+		if len(late) == 0 {
+			return nil
+		}
+		abandoned, err := s.abandoned(ctx, late)
+		if err != nil {
+			return err
+		}
+		if abandoned {
+			return s.release(ctx, late)
+		}
+		return s.charges.FinishPending(ctx, late)
+
+	case psp.ChargeStatusDeclined, psp.ChargeStatusFailed, psp.ChargeStatusVoided:
+		if reason == "" {
+			reason = fmt.Sprintf("PSP reported %s", status)
+		}
+		for _, row := range rows {
+			if row.Status == payment.StatusAuthorized {
+				s.reverseLMS(ctx, row)
+			}
+			var err error
+			if status == psp.ChargeStatusVoided {
+				err = s.payments.Void(ctx, row.ID, reason)
+			} else {
+				err = s.payments.Fail(ctx, row.ID, reason)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// abandoned reports whether the charge lost its purpose while it was
+// pending: the auto-charged order was cancelled in the meantime.
+func (s *service) abandoned(ctx context.Context, rows []payment.Payment) (bool, error) {
+	for _, row := range rows {
+		if row.OrderID == nil {
+			continue
+		}
+		o, err := s.orders.Get(ctx, *row.OrderID)
+		if err != nil {
+			return false, err
+		}
+		if o.Status == order.StatusCancelled || o.Status == order.StatusRefunded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// release voids the authorization of abandoned pending rows rather than
+// take money LMS was never asked to record. The rows are claimed first so a
+// completion racing for them cannot apply them as well.
+func (s *service) release(ctx context.Context, rows []payment.Payment) error {
+	txnID := rows[0].PSPReference
+	claimed, err := s.payments.Claim(ctx, txnID, payment.StatusPending, payment.StatusAuthorized)
+	if err != nil || !claimed {
+		return err
+	}
+
+	reason := "authorized after the order was cancelled"
+	if _, err := s.pspClient.Void(ctx, psp.VoidRequest{TransactionID: txnID}); err != nil {
+		// the hold lapses on its own, as when the collector cannot void
+		s.logger.Error("failed to void abandoned authorization — hold remains until it expires",
+			"transaction_id", txnID,
+			"error", err,
+		)
+		for _, row := range rows {
+			_ = s.payments.Fail(ctx, row.ID, reason)
+		}
+		return nil
+	}
+	for _, row := range rows {
+		if err := s.payments.Void(ctx, row.ID, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseLMS undoes the LMS side of a charge whose capture never completed.
+// LMS cannot undo a loan settlement, so payoffs are left for reconciliation.
+func (s *service) reverseLMS(ctx context.Context, row payment.Payment) {
+	log := s.logger.With("payment_id", row.ID, "loan_id", row.LoanID, "transaction_id", row.PSPReference)
+	if row.InstallmentID == "" {
+		log.Error("capture failed after loan settlement — needs reconciliation")
+		return
+	}
+	if err := s.lmsClient.ReversePayment(ctx, lms.ReversePaymentRequest{
+		LoanID:        row.LoanID,
+		InstallmentID: row.InstallmentID,
+		TransactionID: row.PSPReference,
+	}); err != nil {
+		log.Error("failed to reverse LMS payment — needs reconciliation", "installment_id", row.InstallmentID, "error", err)
+	}
+}
+
+func (s *service) applyRefund(ctx context.Context, rows []payment.Payment, status psp.RefundStatus, reason string) error {
+	if !status.Final() {
+		return nil
+	}
+	if reason == "" {
+		reason = fmt.Sprintf("PSP reported %s", status)
+	}
+
+	for _, row := range rows {
+		var err error
+		if status == psp.RefundStatusRefunded {
+			err = s.payments.Succeed(ctx, row.ID, "")
+		} else {
+			err = s.payments.Fail(ctx, row.ID, reason)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.followUp(ctx, row, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followUp runs the business consequences of a settled refund. Only refunds
+// of cancelled orders have any today: the order is marked refunded once the
+// money is actually back with the customer.
+func (s *service) followUp(ctx context.Context, row payment.Payment, status psp.RefundStatus) error {
+	if row.Source != payment.SourceCancellation || row.OrderID == nil {
+		return nil
+	}
+
+	if status != psp.RefundStatusRefunded {
+		s.logger.Error("refund for cancelled order did not settle — needs manual follow-up",
+			"order_id", *row.OrderID,
+			"payment_id", row.ID,
+			"status", status,
+		)
+		return nil
+	}
+	return s.orders.MarkRefunded(ctx, *row.OrderID)
+}
@@ -0,0 +1,66 @@
+package pspwebhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/payment"
+)
+
+// memPayments serves a fixed set of in-flight rows and records which ones
+// were settled.
+type memPayments struct {
+	payment.Service
+	rows      []payment.Payment
+	succeeded []uuid.UUID
+}
+
+func (m *memPayments) List(context.Context, payment.Filter) ([]payment.Payment, error) {
+	return m.rows, nil
+}
+
+func (m *memPayments) Succeed(_ context.Context, id uuid.UUID, _ string) error {
+	m.succeeded = append(m.succeeded, id)
+	return nil
+}
+
+func TestApply_ChecksAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		settled bool
+	}{
+		{"matching", 25000, true},
+		{"not reported", 0, true},
+		{"partial capture", 10000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a batch: two rows share the PSP transaction
+			payments := &memPayments{rows: []payment.Payment{
+				{ID: uuid.New(), Type: payment.TypeCharge, Status: payment.StatusAuthorized, Amount: 15000, PSPReference: "txn-1"},
+				{ID: uuid.New(), Type: payment.TypeCharge, Status: payment.StatusAuthorized, Amount: 10000, PSPReference: "txn-1"},
+			}}
+			svc := NewService(nil, payments, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			err := svc.Apply(context.Background(), Event{
+				ID:        "evt-1",
+				Type:      EventCharge,
+				Reference: "txn-1",
+				Status:    string(psp.ChargeStatusCaptured),
+				Amount:    tt.amount,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if settled := len(payments.succeeded) == 2; settled != tt.settled {
+				t.Errorf("settled = %v, want %v (succeeded %v)", settled, tt.settled, payments.succeeded)
+			}
+		})
+	}
+}
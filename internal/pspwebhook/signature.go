@@ -0,0 +1,67 @@
+package pspwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign returns the hex HMAC-SHA256 the PSP sends in X-PSP-Signature. The
+// signed message is "<timestamp>.<nonce>.<body>".
+func Sign(secret []byte, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier checks webhook signatures and rejects deliveries whose timestamp
+// is too far from our clock. Nonce reuse within the tolerance is caught by
+// the receipt table.
+type Verifier struct {
+	secret    []byte
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+func NewVerifier(secret string, tolerance time.Duration) *Verifier {
+	return &Verifier{secret: []byte(secret), tolerance: tolerance, now: time.Now}
+}
+
+// Verify checks the signature headers against the raw request body.
+// timestamp is in Unix seconds.
+func (v *Verifier) Verify(signature, timestamp, nonce string, body []byte) error {
+	if len(v.secret) == 0 || signature == "" || nonce == "" {
+		return ErrInvalidSignature
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	skew := v.now().Sub(time.Unix(sec, 0))
+	if skew > v.tolerance || skew < -v.tolerance {
+		return ErrStaleTimestamp
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	want, _ := hex.DecodeString(Sign(v.secret, timestamp, nonce, body))
+	if !hmac.Equal(got, want) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package pspwebhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	secret := []byte("whsec-test")
+	body := []byte(`{"id":"evt-1","type":"refund","reference":"ref-1","status":"refunded"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign(secret, ts, "nonce-1", body)
+
+	v := NewVerifier(string(secret), 5*time.Minute)
+	v.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp string
+		nonce     string
+		body      []byte
+		wantErr   error
+	}{
+		{name: "valid", signature: sig, timestamp: ts, nonce: "nonce-1", body: body},
+		{name: "tampered body", signature: sig, timestamp: ts, nonce: "nonce-1", body: []byte(`{}`), wantErr: ErrInvalidSignature},
+		{name: "different nonce", signature: sig, timestamp: ts, nonce: "nonce-2", body: body, wantErr: ErrInvalidSignature},
+		{name: "missing signature", signature: "", timestamp: ts, nonce: "nonce-1", body: body, wantErr: ErrInvalidSignature},
+		{name: "malformed signature", signature: "not-hex", timestamp: ts, nonce: "nonce-1", body: body, wantErr: ErrInvalidSignature},
+		{name: "malformed timestamp", signature: sig, timestamp: "yesterday", nonce: "nonce-1", body: body, wantErr: ErrInvalidSignature},
+		{
+			name:      "too old",
+			signature: Sign(secret, strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10), "nonce-1", body),
+			timestamp: strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10),
+			nonce:     "nonce-1",
+			body:      body,
+			wantErr:   ErrStaleTimestamp,
+		},
+		{
+			name:      "from the future",
+			signature: Sign(secret, strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10), "nonce-1", body),
+			timestamp: strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10),
+			nonce:     "nonce-1",
+			body:      body,
+			wantErr:   ErrStaleTimestamp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Verify(tt.signature, tt.timestamp, tt.nonce, tt.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerify_NoSecretRejectsEverything(t *testing.T) {
+	now := time.Now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{}`)
+
+	v := NewVerifier("", 5*time.Minute)
+	err := v.Verify(Sign(nil, ts, "nonce-1", body), ts, "nonce-1", body)
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
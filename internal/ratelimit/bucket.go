@@ -0,0 +1,55 @@
+// Package ratelimit throttles API callers with token buckets. A bucket holds
+// up to Limit.Burst tokens, refills at Limit.Rate per second, and every
+// request takes one token.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket's shape.
+type Limit struct {
+	Rate  float64 // tokens added per second
+	Burst int     // bucket capacity
+}
+
+// PerMinute allows n requests a minute, all of which may come at once.
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Store keeps buckets by key. Take removes a token from the bucket if one is
+// available; otherwise it reports how long until one will be.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// bucket is the state a Store persists per key.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// take refills b up to now and tries to take one token.
+func (b *bucket) take(limit Limit, now time.Time) (bool, time.Duration) {
+	if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	}
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if limit.Rate <= 0 {
+		return false, time.Hour
+	}
+	wait := (1 - b.tokens) / limit.Rate
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{tokens: float64(limit.Burst), updatedAt: now}
+}
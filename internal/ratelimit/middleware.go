@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// Rules are the limits the middleware enforces. A zero Limit is not
+// enforced. Routes are keyed by "<METHOD> <route pattern>", e.g.
+// "POST /api/v1/orders/:id/cancel", and apply per user.
+type Rules struct {
+	PerIP   Limit
+	PerUser Limit
+	Routes  map[string]Limit
+}
+
+// ParseRoutes reads route limits in the form
+// "POST /api/v1/installments/pay=10;POST /api/v1/orders/:id/cancel=5", each
+// number being requests per minute.
+func ParseRoutes(spec string) (map[string]Limit, error) {
+	routes := map[string]Limit{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		route, n, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("route limit %q is not <METHOD> <path>=<per minute>", part)
+		}
+		perMinute, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || perMinute <= 0 {
+			return nil, fmt.Errorf("route limit %q: invalid rate %q", part, n)
+		}
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok {
+			return nil, fmt.Errorf("route limit %q has no method", part)
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = PerMinute(perMinute)
+	}
+	return routes, nil
+}
+
+// Limiter builds the rate-limiting middleware.
+type Limiter struct {
+	store  Store
+	rules  Rules
+	logger *slog.Logger
+}
+
+func NewLimiter(store Store, rules Rules, logger *slog.Logger) *Limiter {
+	return &Limiter{store: store, rules: rules, logger: logger}
+}
+
+// ByIP limits requests per client IP. It runs before authentication so
+// anonymous floods are throttled too.
+func (l *Limiter) ByIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.rules.PerIP.Burst > 0 && !l.allow(c, "ip:"+c.ClientIP(), l.rules.PerIP) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// ByUser limits requests per authenticated customer, overall and per route.
+// It must run after auth.Authenticate.
+func (l *Limiter) ByUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+		user := p.UserID.String()
+
+		if l.rules.PerUser.Burst > 0 && !l.allow(c, "user:"+user, l.rules.PerUser) {
+			return
+		}
+		route := c.Request.Method + " " + c.FullPath()
+		if limit, ok := l.rules.Routes[route]; ok && !l.allow(c, "route:"+route+":"+user, limit) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow takes a token for key, aborting the request when none is left. If
+// the store fails the request is let through: an outage of the limiter must
+// not take the API down with it.
+func (l *Limiter) allow(c *gin.Context, key string, limit Limit) bool {
+	allowed, retryAfter, err := l.store.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		l.logger.Error("rate limit store failed — allowing request", "key", key, "error", err)
+		return true
+	}
+	if allowed {
+		return true
+	}
+
+	_ = c.Error(apperror.NewRateLimited("too many requests", max(retryAfter, time.Second)).Localized("error.rate_limited"))
+	c.Abort()
+	return false
+}
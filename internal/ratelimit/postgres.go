@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bucketRow is a bucket shared by every replica.
+type bucketRow struct {
+	Key       string    `gorm:"type:varchar(255);primaryKey"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (bucketRow) TableName() string { return "rate_limit_buckets" }
+
+// PostgresStore keeps buckets in Postgres so limits hold across replicas.
+// Each Take locks its bucket row for the duration of a short transaction.
+type PostgresStore struct {
+	db  *gorm.DB
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db, now: time.Now}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := s.now().UTC()
+	s.prune(ctx, now)
+
+	var (
+		allowed    bool
+		retryAfter time.Duration
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&bucketRow{Key: key, Tokens: float64(limit.Burst), UpdatedAt: now}).Error; err != nil {
+			return err
+		}
+
+		var row bucketRow
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&row, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		b := bucket{tokens: row.Tokens, updatedAt: row.UpdatedAt}
+		allowed, retryAfter = b.take(limit, now)
+
+		return tx.Model(&bucketRow{}).Where("key = ?", key).
+			Updates(map[string]any{"tokens": b.tokens, "updated_at": b.updatedAt}).Error
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, retryAfter, nil
+}
+
+// prune deletes idle buckets, at most once a minute per replica. Failures
+// are ignored; the next prune retries.
+func (s *PostgresStore) prune(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPrune) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.mu.Unlock()
+
+	s.db.WithContext(ctx).Where("updated_at < ?", now.Add(-idleTTL)).Delete(&bucketRow{})
+}
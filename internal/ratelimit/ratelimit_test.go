@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/auth"
+	"github.com/example/ppo/internal/middleware"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := s.Take(ctx, "k", limit); !ok {
+			t.Fatalf("request %d: expected burst to be allowed", i+1)
+		}
+	}
+
+	ok, retryAfter, _ := s.Take(ctx, "k", limit)
+	if ok {
+		t.Fatal("expected empty bucket to refuse")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("expected retry after 1s, got %s", retryAfter)
+	}
+
+	if ok, _, _ := s.Take(ctx, "other", limit); !ok {
+		t.Error("expected buckets to be independent")
+	}
+
+	now = now.Add(1500 * time.Millisecond)
+	if ok, _, _ := s.Take(ctx, "k", limit); !ok {
+		t.Error("expected a token after refill")
+	}
+	ok, retryAfter, _ = s.Take(ctx, "k", limit)
+	if ok || retryAfter != 500*time.Millisecond {
+		t.Errorf("expected refusal with 500ms wait, got ok=%v retryAfter=%s", ok, retryAfter)
+	}
+
+	// refill never exceeds the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		s.Take(ctx, "k", limit)
+	}
+	if ok, _, _ := s.Take(ctx, "k", limit); ok {
+		t.Error("expected bucket capped at burst")
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("post /api/v1/installments/pay=10; POST /api/v1/orders/:id/cancel=5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := routes["POST /api/v1/orders/:id/cancel"]; got != PerMinute(5) {
+		t.Errorf("unexpected cancel limit %+v", got)
+	}
+	if _, ok := routes["POST /api/v1/installments/pay"]; !ok {
+		t.Error("expected method to be upper-cased")
+	}
+
+	for _, bad := range []string{"POST /pay", "/pay=10", "POST /pay=0", "POST /pay=many"} {
+		if _, err := ParseRoutes(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("database down")
+}
+
+func newTestRouter(store Store, rules Rules, userID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	l := NewLimiter(store, rules, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	setUser := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), &auth.Principal{UserID: userID}))
+	}
+	api := r.Group("/api/v1", l.ByIP(), setUser, l.ByUser())
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	api.POST("/installments/pay", ok)
+	api.GET("/users/:userId/installments", ok)
+	return r
+}
+
+func do(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestLimiter_RouteLimit(t *testing.T) {
+	r := newTestRouter(NewMemoryStore(), Rules{
+		Routes: map[string]Limit{"POST /api/v1/installments/pay": PerMinute(2)},
+	}, uuid.New())
+
+	for i := 0; i < 2; i++ {
+		if w := do(r, http.MethodPost, "/api/v1/installments/pay"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected 204, got %d", i+1, w.Code)
+		}
+	}
+
+	w := do(r, http.MethodPost, "/api/v1/installments/pay")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("expected Retry-After 30, got %q", got)
+	}
+
+	// other routes are not covered by the route limit
+	if w := do(r, http.MethodGet, "/api/v1/users/x/installments"); w.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for unlimited route, got %d", w.Code)
+	}
+}
+
+func TestLimiter_PerIPAndPerUser(t *testing.T) {
+	r := newTestRouter(NewMemoryStore(), Rules{PerIP: PerMinute(1)}, uuid.New())
+	do(r, http.MethodGet, "/api/v1/users/x/installments")
+	if w := do(r, http.MethodGet, "/api/v1/users/x/installments"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected per-IP 429, got %d", w.Code)
+	}
+
+	r = newTestRouter(NewMemoryStore(), Rules{PerUser: PerMinute(1)}, uuid.New())
+	do(r, http.MethodGet, "/api/v1/users/x/installments")
+	if w := do(r, http.MethodPost, "/api/v1/installments/pay"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected per-user 429, got %d", w.Code)
+	}
+}
+
+func TestLimiter_StoreFailureAllows(t *testing.T) {
+	r := newTestRouter(failingStore{}, Rules{PerIP: PerMinute(1), PerUser: PerMinute(1)}, uuid.New())
+
+	for i := 0; i < 3; i++ {
+		if w := do(r, http.MethodGet, "/api/v1/users/x/installments"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected 204 while the store is down, got %d", i+1, w.Code)
+		}
+	}
+}
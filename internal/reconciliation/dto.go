@@ -0,0 +1,75 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunRequest selects the reconciliation window. Both bounds are optional and
+// default to the last 24 hours.
+type RunRequest struct {
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+type RunResponse struct {
+	ID               uuid.UUID             `json:"id"`
+	WindowFrom       string                `json:"window_from"`
+	WindowTo         string                `json:"window_to"`
+	Status           RunStatus             `json:"status"`
+	CheckedCount     int                   `json:"checked_count"`
+	DiscrepancyCount int                   `json:"discrepancy_count"`
+	HealedCount      int                   `json:"healed_count"`
+	Error            string                `json:"error,omitempty"`
+	StartedAt        string                `json:"started_at"`
+	FinishedAt       string                `json:"finished_at,omitempty"`
+	Discrepancies    []DiscrepancyResponse `json:"discrepancies,omitempty"`
+}
+
+type DiscrepancyResponse struct {
+	Kind          Kind   `json:"kind"`
+	PSPReference  string `json:"psp_reference,omitempty"`
+	PaymentType   string `json:"payment_type,omitempty"`
+	LoanID        string `json:"loan_id,omitempty"`
+	InstallmentID string `json:"installment_id,omitempty"`
+	LocalAmount   int64  `json:"local_amount"`
+	PSPAmount     int64  `json:"psp_amount"`
+	LMSAmount     int64  `json:"lms_amount"`
+	Healed        bool   `json:"healed"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+const timeFormat = "2006-01-02T15:04:05Z"
+
+func ToResponse(r *Run) RunResponse {
+	resp := RunResponse{
+		ID:               r.ID,
+		WindowFrom:       r.WindowFrom.UTC().Format(timeFormat),
+		WindowTo:         r.WindowTo.UTC().Format(timeFormat),
+		Status:           r.Status,
+		CheckedCount:     r.CheckedCount,
+		DiscrepancyCount: r.DiscrepancyCount,
+		HealedCount:      r.HealedCount,
+		Error:            r.Error,
+		StartedAt:        r.StartedAt.UTC().Format(timeFormat),
+	}
+	if r.FinishedAt != nil {
+		resp.FinishedAt = r.FinishedAt.UTC().Format(timeFormat)
+	}
+	for _, d := range r.Discrepancies {
+		resp.Discrepancies = append(resp.Discrepancies, DiscrepancyResponse{
+			Kind:          d.Kind,
+			PSPReference:  d.PSPReference,
+			PaymentType:   d.PaymentType,
+			LoanID:        d.LoanID,
+			InstallmentID: d.InstallmentID,
+			LocalAmount:   d.LocalAmount,
+			PSPAmount:     d.PSPAmount,
+			LMSAmount:     d.LMSAmount,
+			Healed:        d.Healed,
+			Detail:        d.Detail,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,84 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	recon := rg.Group("/reconciliations")
+	recon.POST("", h.RunReconciliation)
+	recon.GET("", h.ListRuns)
+	recon.GET("/:id", h.GetRun)
+}
+
+func (h *Handler) RunReconciliation(c *gin.Context) {
+	var req RunRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(apperror.NewBindingError(err))
+			return
+		}
+	}
+
+	to := time.Now().UTC()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.Add(-24 * time.Hour)
+	if req.From != nil {
+		from = *req.From
+	}
+
+	run, err := h.svc.Run(c.Request.Context(), from, to)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(run))
+}
+
+func (h *Handler) ListRuns(c *gin.Context) {
+	runs, err := h.svc.ListRuns(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]RunResponse, len(runs))
+	for i := range runs {
+		out[i] = ToResponse(&runs[i])
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) GetRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid reconciliation run id", apperror.Field("id", "uuid")).
+			Localized("reconciliation.invalid_id"))
+		return
+	}
+
+	run, err := h.svc.GetRun(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(run))
+}
@@ -0,0 +1,67 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusCompleted RunStatus = "completed"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// Kind classifies how the local ledger, the PSP and LMS disagree.
+type Kind string
+
+const (
+	// KindMissingInLMS: money moved at the PSP but LMS never recorded it.
+	KindMissingInLMS Kind = "missing_in_lms"
+	// KindMissingInPSP: the local ledger claims success but the PSP has no
+	// such transaction.
+	KindMissingInPSP Kind = "missing_in_psp"
+	// KindMissingLocally: the PSP has a transaction we never recorded.
+	KindMissingLocally Kind = "missing_locally"
+	// KindAmountMismatch: all sides know the transaction but disagree on
+	// the amount.
+	KindAmountMismatch Kind = "amount_mismatch"
+	// KindStatusMismatch: the local ledger claims success but the PSP never
+	// settled the transaction (declined, voided, failed or still open).
+	KindStatusMismatch Kind = "status_mismatch"
+)
+
+type Run struct {
+	ID               uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	WindowFrom       time.Time     `gorm:"not null"`
+	WindowTo         time.Time     `gorm:"not null"`
+	Status           RunStatus     `gorm:"type:varchar(20);not null;default:'running'"`
+	CheckedCount     int           `gorm:"not null;default:0"`
+	DiscrepancyCount int           `gorm:"not null;default:0"`
+	HealedCount      int           `gorm:"not null;default:0"`
+	Error            string        `gorm:"type:text;not null;default:''"`
+	Discrepancies    []Discrepancy `gorm:"foreignKey:RunID"`
+	StartedAt        time.Time     `gorm:"not null"`
+	FinishedAt       *time.Time
+}
+
+type Discrepancy struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RunID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Kind          Kind      `gorm:"type:varchar(32);not null"`
+	PSPReference  string    `gorm:"column:psp_reference;type:varchar(128);not null;default:''"`
+	PaymentType   string    `gorm:"type:varchar(20);not null;default:''"`
+	LoanID        string    `gorm:"type:varchar(64);not null;default:''"`
+	InstallmentID string    `gorm:"type:varchar(64);not null;default:''"`
+	LocalAmount   int64     `gorm:"not null;default:0"`
+	PSPAmount     int64     `gorm:"column:psp_amount;not null;default:0"`
+	LMSAmount     int64     `gorm:"column:lms_amount;not null;default:0"`
+	Healed        bool      `gorm:"not null;default:false"`
+	Detail        string    `gorm:"type:text;not null;default:''"`
+	CreatedAt     time.Time
+}
+
+func (Run) TableName() string         { return "reconciliation_runs" }
+func (Discrepancy) TableName() string { return "reconciliation_discrepancies" }
@@ -0,0 +1,85 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+type Repository interface {
+	CreateRun(ctx context.Context, run *Run) error
+	// SaveResult stores the run's final counters together with its
+	// discrepancies in one transaction.
+	SaveResult(ctx context.Context, run *Run) error
+	GetRun(ctx context.Context, id uuid.UUID) (*Run, error)
+	ListRuns(ctx context.Context, limit int) ([]Run, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateRun(ctx context.Context, run *Run) error {
+	if err := r.db.WithContext(ctx).Omit("Discrepancies").Create(run).Error; err != nil {
+		return apperror.NewInternal("creating reconciliation run", err)
+	}
+	return nil
+}
+
+func (r *repository) SaveResult(ctx context.Context, run *Run) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Run{}).Where("id = ?", run.ID).Updates(map[string]any{
+			"status":            run.Status,
+			"checked_count":     run.CheckedCount,
+			"discrepancy_count": run.DiscrepancyCount,
+			"healed_count":      run.HealedCount,
+			"error":             run.Error,
+			"finished_at":       run.FinishedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		for i := range run.Discrepancies {
+			run.Discrepancies[i].RunID = run.ID
+		}
+		if len(run.Discrepancies) > 0 {
+			if err := tx.Create(&run.Discrepancies).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apperror.NewInternal("saving reconciliation result", err)
+	}
+	return nil
+}
+
+func (r *repository) GetRun(ctx context.Context, id uuid.UUID) (*Run, error) {
+	var run Run
+	err := r.db.WithContext(ctx).Preload("Discrepancies").First(&run, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NewNotFound(fmt.Sprintf("reconciliation run %s not found", id)).Localized("reconciliation.not_found", "run", id)
+	}
+	if err != nil {
+		return nil, apperror.NewInternal("fetching reconciliation run", err)
+	}
+	return &run, nil
+}
+
+func (r *repository) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	var runs []Run
+	if err := r.db.WithContext(ctx).Order("started_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, apperror.NewInternal("listing reconciliation runs", err)
+	}
+	return runs, nil
+}
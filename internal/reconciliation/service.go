@@ -0,0 +1,357 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/client/psp"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+const (
+	ledgerPageSize = 500
+	pspPageSize    = 200
+	listRunsLimit  = 50
+)
+
+type Service interface {
+	// Run compares every succeeded payment created in [from, to) against the
+	// PSP and LMS, heals the safe cases and stores a discrepancy report.
+	Run(ctx context.Context, from, to time.Time) (*Run, error)
+	GetRun(ctx context.Context, id uuid.UUID) (*Run, error)
+	ListRuns(ctx context.Context) ([]Run, error)
+}
+
+type service struct {
+	repo      Repository
+	payments  payment.Service
+	lmsClient lms.Client
+	pspClient psp.Client
+	logger    *slog.Logger
+}
+
+func NewService(
+	repo Repository,
+	payments payment.Service,
+	lmsClient lms.Client,
+	pspClient psp.Client,
+	logger *slog.Logger,
+) Service {
+	return &service{
+		repo:      repo,
+		payments:  payments,
+		lmsClient: lmsClient,
+		pspClient: pspClient,
+		logger:    logger,
+	}
+}
+
+func (s *service) GetRun(ctx context.Context, id uuid.UUID) (*Run, error) {
+	return s.repo.GetRun(ctx, id)
+}
+
+func (s *service) ListRuns(ctx context.Context) ([]Run, error) {
+	return s.repo.ListRuns(ctx, listRunsLimit)
+}
+
+func (s *service) Run(ctx context.Context, from, to time.Time) (*Run, error) {
+	if !from.Before(to) {
+		return nil, apperror.NewValidation("reconciliation window start must be before its end").
+			Localized("reconciliation.invalid_window")
+	}
+
+	run := &Run{
+		WindowFrom: from.UTC(),
+		WindowTo:   to.UTC(),
+		Status:     RunStatusRunning,
+		StartedAt:  time.Now().UTC(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	log := s.logger.With("run_id", run.ID, "from", run.WindowFrom, "to", run.WindowTo)
+	log.Info("reconciliation started")
+
+	err := s.reconcile(ctx, run)
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.DiscrepancyCount = len(run.Discrepancies)
+	run.Status = RunStatusCompleted
+	if err != nil {
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+	}
+
+	if saveErr := s.repo.SaveResult(ctx, run); saveErr != nil {
+		return nil, saveErr
+	}
+
+	if err != nil {
+		log.Error("reconciliation failed", "error", err)
+		return run, apperror.NewUpstream("reconciling payments", err)
+	}
+
+	log.Info("reconciliation finished",
+		"checked", run.CheckedCount,
+		"discrepancies", run.DiscrepancyCount,
+		"healed", run.HealedCount,
+	)
+	return run, nil
+}
+
+// ledgerGroup is every local row for one PSP reference; a batch checkout has
+// several rows sharing one charge.
+type ledgerGroup struct {
+	reference string
+	typ       payment.Type
+	rows      []payment.Payment
+	total     int64
+}
+
+func (s *service) reconcile(ctx context.Context, run *Run) error {
+	groups, err := s.loadLedger(ctx, run.WindowFrom, run.WindowTo)
+	if err != nil {
+		return err
+	}
+
+	pspTxns, err := s.loadPSP(ctx, run.WindowFrom, run.WindowTo)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		run.CheckedCount++
+
+		txn, ok := pspTxns[g.reference]
+		if ok {
+			delete(pspTxns, g.reference)
+		} else {
+			// the PSP may have timestamped it just outside our window
+			txn, err = s.pspClient.GetTransaction(ctx, g.reference)
+			if errors.Is(err, psp.ErrNotFound) {
+				run.add(Discrepancy{
+					Kind:         KindMissingInPSP,
+					PSPReference: g.reference,
+					PaymentType:  string(g.typ),
+					LoanID:       g.rows[0].LoanID,
+					LocalAmount:  g.total,
+					Detail:       "local ledger records a succeeded payment the PSP does not know",
+				})
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("fetching PSP transaction %s: %w", g.reference, err)
+			}
+		}
+
+		if !settled(txn, g.typ) {
+			run.add(Discrepancy{
+				Kind:         KindStatusMismatch,
+				PSPReference: g.reference,
+				PaymentType:  string(g.typ),
+				LoanID:       g.rows[0].LoanID,
+				LocalAmount:  g.total,
+				PSPAmount:    txn.Amount,
+				Detail:       fmt.Sprintf("local ledger records a succeeded %s the PSP reports as %s", g.typ, txn.Status),
+			})
+			continue
+		}
+
+		if txn.Amount != g.total {
+			run.add(Discrepancy{
+				Kind:         KindAmountMismatch,
+				PSPReference: g.reference,
+				PaymentType:  string(g.typ),
+				LoanID:       g.rows[0].LoanID,
+				LocalAmount:  g.total,
+				PSPAmount:    txn.Amount,
+				Detail:       "local ledger and PSP disagree on the amount",
+			})
+			continue
+		}
+
+		// LMS only tracks money applied to loans, not refunds
+		if g.typ == payment.TypeCharge {
+			if err := s.checkLMS(ctx, run, g, txn); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, txn := range pspTxns {
+		run.add(Discrepancy{
+			Kind:         KindMissingLocally,
+			PSPReference: txn.ID,
+			PaymentType:  txn.Type,
+			PSPAmount:    txn.Amount,
+			Detail:       fmt.Sprintf("PSP %s transaction (status %s) has no succeeded local payment", txn.Type, txn.Status),
+		})
+	}
+
+	return nil
+}
+
+// checkLMS compares each local row of a charge with what LMS recorded for the
+// same transaction. An installment payment missing from LMS is re-recorded
+// automatically: the PSP confirmed the money with a matching amount, so
+// replaying RecordPayment is safe. Settlements and mismatches are only
+// reported.
+func (s *service) checkLMS(ctx context.Context, run *Run, g ledgerGroup, txn *psp.Transaction) error {
+	lmsPayments, err := s.lmsClient.GetPayments(ctx, g.reference)
+	if err != nil {
+		return fmt.Errorf("fetching LMS payments for %s: %w", g.reference, err)
+	}
+
+	recorded := make(map[string]int64, len(lmsPayments))
+	for _, p := range lmsPayments {
+		recorded[p.InstallmentID] += p.Amount
+	}
+
+	for _, row := range g.rows {
+		lmsAmount, ok := recorded[row.InstallmentID]
+
+		switch {
+		case ok && lmsAmount != row.Amount:
+			run.add(Discrepancy{
+				Kind:          KindAmountMismatch,
+				PSPReference:  g.reference,
+				PaymentType:   string(g.typ),
+				LoanID:        row.LoanID,
+				InstallmentID: row.InstallmentID,
+				LocalAmount:   row.Amount,
+				PSPAmount:     txn.Amount,
+				LMSAmount:     lmsAmount,
+				Detail:        "LMS recorded a different amount than the local ledger",
+			})
+
+		case !ok && row.InstallmentID == "":
+			run.add(Discrepancy{
+				Kind:         KindMissingInLMS,
+				PSPReference: g.reference,
+				PaymentType:  string(g.typ),
+				LoanID:       row.LoanID,
+				LocalAmount:  row.Amount,
+				PSPAmount:    txn.Amount,
+				Detail:       "loan settlement missing in LMS; replay it manually",
+			})
+
+		case !ok:
+			d := Discrepancy{
+				Kind:          KindMissingInLMS,
+				PSPReference:  g.reference,
+				PaymentType:   string(g.typ),
+				LoanID:        row.LoanID,
+				InstallmentID: row.InstallmentID,
+				LocalAmount:   row.Amount,
+				PSPAmount:     txn.Amount,
+			}
+			if err := s.lmsClient.RecordPayment(ctx, lms.RecordPaymentRequest{
+				LoanID:        row.LoanID,
+				InstallmentID: row.InstallmentID,
+				Amount:        row.Amount,
+				TransactionID: g.reference,
+			}); err != nil {
+				d.Detail = fmt.Sprintf("auto-heal failed: %v", err)
+			} else {
+				d.Healed = true
+				d.Detail = "payment re-recorded in LMS"
+				run.HealedCount++
+			}
+			run.add(d)
+		}
+	}
+	return nil
+}
+
+func (r *Run) add(d Discrepancy) {
+	r.Discrepancies = append(r.Discrepancies, d)
+}
+
+func (s *service) loadLedger(ctx context.Context, from, to time.Time) ([]ledgerGroup, error) {
+	byRef := map[string]*ledgerGroup{}
+	var order []string
+
+	for offset := 0; ; offset += ledgerPageSize {
+		rows, err := s.payments.List(ctx, payment.Filter{
+			Status: payment.StatusSucceeded,
+			From:   from,
+			To:     to,
+			Limit:  ledgerPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing local payments: %w", err)
+		}
+
+		for _, row := range rows {
+			if row.PSPReference == "" {
+				continue
+			}
+			g, ok := byRef[row.PSPReference]
+			if !ok {
+				g = &ledgerGroup{reference: row.PSPReference, typ: row.Type}
+				byRef[row.PSPReference] = g
+				order = append(order, row.PSPReference)
+			}
+			g.rows = append(g.rows, row)
+			g.total += row.Amount
+		}
+
+		if len(rows) < ledgerPageSize {
+			break
+		}
+	}
+
+	groups := make([]ledgerGroup, len(order))
+	for i, ref := range order {
+		groups[i] = *byRef[ref]
+	}
+	return groups, nil
+}
+
+// settled reports whether txn is a finished money movement of type typ: a
+// captured charge or a completed refund.
+func settled(txn *psp.Transaction, typ payment.Type) bool {
+	switch typ {
+	case payment.TypeCharge:
+		return txn.Status == string(psp.ChargeStatusCaptured)
+	case payment.TypeRefund:
+		return txn.Status == string(psp.RefundStatusRefunded)
+	default:
+		return false
+	}
+}
+
+// loadPSP returns the window's settled PSP transactions by ID. Declined,
+// voided and failed authorizations moved no money and never have a succeeded
+// local row, so they are left out rather than reported as missing locally.
+func (s *service) loadPSP(ctx context.Context, from, to time.Time) (map[string]*psp.Transaction, error) {
+	txns := map[string]*psp.Transaction{}
+	req := psp.ListTransactionsRequest{From: from, To: to, Limit: pspPageSize}
+
+	for {
+		page, err := s.pspClient.ListTransactions(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("listing PSP transactions: %w", err)
+		}
+		for i := range page.Items {
+			txn := &page.Items[i]
+			if settled(txn, payment.Type(txn.Type)) {
+				txns[txn.ID] = txn
+			}
+		}
+		if page.NextCursor == "" {
+			return txns, nil
+		}
+		req.Cursor = page.NextCursor
+	}
+}
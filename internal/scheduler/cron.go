@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -10,6 +11,11 @@ import (
 	"github.com/example/ppo/internal/client/lms"
 	"github.com/example/ppo/internal/client/psp"
 	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/internal/pspwebhook"
+	"github.com/example/ppo/internal/reconciliation"
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/redact"
 )
 
 type Scheduler struct {
@@ -17,13 +23,25 @@ type Scheduler struct {
 	lmsClient lms.Client
 	pspClient psp.Client
 	orderRepo order.Repository
+	payments  payment.Service
+	collector *payment.Collector
+	reconcile reconciliation.Service
+	resolver  pspwebhook.Service
+	dryRun    bool
+	inFlight  *inFlight
 	logger    *slog.Logger
 }
 
+// New builds the scheduler. When dryRun is true every auto-charge run is
+// forced into report-only mode, regardless of how it was triggered.
 func New(
 	lmsClient lms.Client,
 	pspClient psp.Client,
 	orderRepo order.Repository,
+	payments payment.Service,
+	reconcile reconciliation.Service,
+	resolver pspwebhook.Service,
+	dryRun bool,
 	logger *slog.Logger,
 ) *Scheduler {
 	return &Scheduler{
@@ -31,6 +49,12 @@ func New(
 		lmsClient: lmsClient,
 		pspClient: pspClient,
 		orderRepo: orderRepo,
+		payments:  payments,
+		collector: payment.NewCollector(pspClient, payments, logger),
+		reconcile: reconcile,
+		resolver:  resolver,
+		dryRun:    dryRun,
+		inFlight:  newInFlight(),
 		logger:    logger,
 	}
 }
@@ -44,6 +68,14 @@ func (s *Scheduler) Start() error {
 		return err
 	}
 
+	if _, err := s.cron.AddFunc("0 0 4 * * *", s.reconcilePayments); err != nil {
+		return err
+	}
+
+	if _, err := s.cron.AddFunc("0 */15 * * * *", s.resolvePendingPayments); err != nil {
+		return err
+	}
+
 	s.cron.Start()
 	return nil
 }
@@ -56,13 +88,11 @@ func (s *Scheduler) sendReminders() {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	upcoming, err := s.lmsClient.GetUpcomingInstallments(ctx)
-	if err != nil {
-		s.logger.Error("failed to fetch upcoming installments", "error", err)
-		return
-	}
-
-	for _, inst := range upcoming {
+	it := lms.NewInstallmentIterator(s.lmsClient, lms.ListInstallmentsRequest{
+		Status: lms.InstallmentStatusUpcoming,
+	})
+	for it.Next(ctx) {
+		inst := it.Installment()
 		s.logger.Info("payment reminder",
 			"loan_id", inst.LoanID,
 			"installment_id", inst.ID,
@@ -70,56 +100,211 @@ func (s *Scheduler) sendReminders() {
 			"amount", inst.Amount,
 		)
 	}
+	if err := it.Err(); err != nil {
+		s.logger.Error("failed to fetch upcoming installments", "error", err)
+	}
 }
 
-// autoChargeOverdue fetches overdue installments from LMS and
-// charges the card we have stored on the corresponding order.
+// reconcilePayments runs the three-way reconciliation for the previous UTC
+// day. It is scheduled after the auto-charge job so that day's charges have
+// settled. The run stores its own report; here we only log the outcome.
+func (s *Scheduler) reconcilePayments() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.Add(-24 * time.Hour)
+
+	if _, err := s.reconcile.Run(ctx, from, to); err != nil {
+		s.logger.Error("scheduled reconciliation failed", "error", err)
+	}
+}
+
+// autoChargeOverdue is the cron entry point for RunAutoCharge.
 func (s *Scheduler) autoChargeOverdue() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	overdue, err := s.lmsClient.GetOverdueInstallments(ctx)
+	report, err := s.RunAutoCharge(ctx, false)
 	if err != nil {
-		s.logger.Error("failed to fetch overdue installments", "error", err)
-		return
+		s.logger.Error("auto-charge run failed", "error", err)
+		if report == nil {
+			return
+		}
+	}
+
+	s.logger.Info("auto-charge run finished",
+		"dry_run", report.DryRun,
+		"summary", report.Summary,
+	)
+}
+
+// RunAutoCharge streams overdue installments from LMS page by page and
+// charges the card we have stored on the corresponding order: the card is
+// authorized, LMS records the payment, and only then is the charge captured.
+// Installments that fail the eligibility rules are skipped and reported with
+// a reason. In dry-run mode the full matching logic runs but PSP and LMS are
+// never called; the report lists the charges that would have been made. If
+// LMS fails mid-listing the partial report is returned alongside the error.
+func (s *Scheduler) RunAutoCharge(ctx context.Context, dryRun bool) (*AutoChargeReport, error) {
+	report := &AutoChargeReport{
+		DryRun:    dryRun || s.dryRun,
+		StartedAt: time.Now().UTC(),
+		Results:   []ChargeResult{},
+		Summary:   map[Outcome]int{},
+	}
+
+	it := lms.NewInstallmentIterator(s.lmsClient, lms.ListInstallmentsRequest{
+		Status: lms.InstallmentStatusOverdue,
+	})
+	for it.Next(ctx) {
+		report.add(s.processOverdueInstallment(ctx, it.Installment(), report.DryRun))
 	}
 
-	for _, inst := range overdue {
-		s.processOverdueInstallment(ctx, inst)
+	report.FinishedAt = time.Now().UTC()
+	if err := it.Err(); err != nil {
+		return report, apperror.NewUpstream("fetching overdue installments from LMS", err).
+			Localized("upstream.lms")
 	}
+	return report, nil
 }
 
-func (s *Scheduler) processOverdueInstallment(ctx context.Context, inst lms.Installment) {
-	log := s.logger.With("loan_id", inst.LoanID, "installment_id", inst.ID)
+func (s *Scheduler) processOverdueInstallment(
+	ctx context.Context,
+	inst lms.Installment,
+	dryRun bool,
+) ChargeResult {
+	log := s.logger.With("loan_id", inst.LoanID, "installment_id", inst.ID, "dry_run", dryRun)
+
+	result := ChargeResult{
+		LoanID:        inst.LoanID,
+		InstallmentID: inst.ID,
+		Amount:        inst.Amount,
+	}
 
 	matched, err := s.orderRepo.FindByLoanID(ctx, inst.LoanID)
 	if err != nil {
 		log.Error("no order found for overdue installment", "error", err)
-		return
+		result.Outcome = OutcomeSkipped
+		result.Reason = "no order found for loan"
+		return result
 	}
 
-	chargeResp, err := s.pspClient.Charge(ctx, psp.ChargeRequest{
-		Amount:    inst.Amount,
-		Currency:  matched.Currency,
-		CardToken: matched.CardToken,
+	result.OrderID = matched.ID.String()
+	result.Currency = matched.Currency
+	result.CardToken = redact.CardToken(matched.CardToken)
+
+	if err := s.checkEligibility(ctx, inst, matched); err != nil {
+		var skip *ineligibleError
+		if errors.As(err, &skip) {
+			log.Warn("overdue installment not eligible for auto-charge", "reason", skip.reason)
+			result.Outcome = OutcomeSkipped
+			result.Reason = skip.reason
+			return result
+		}
+		log.Error("eligibility check failed", "error", err)
+		result.Outcome = OutcomeFailed
+		result.Reason = "could not verify eligibility"
+		return result
+	}
+
+	if dryRun {
+		if s.inFlight.has(inst.ID) {
+			result.Outcome = OutcomeSkipped
+			result.Reason = "charge already in flight"
+			return result
+		}
+		log.Info("auto-charge dry run: would charge",
+			"order_id", result.OrderID,
+			"amount", inst.Amount,
+		)
+		result.Outcome = OutcomeWouldCharge
+		return result
+	}
+
+	if !s.inFlight.acquire(inst.ID) {
+		log.Warn("skipping installment with a charge already in flight")
+		result.Outcome = OutcomeSkipped
+		result.Reason = "charge already in flight"
+		return result
+	}
+	defer s.inFlight.release(inst.ID)
+
+	txnID, err := s.collector.Collect(ctx, payment.Collection{
+		Payments: []*payment.Payment{{
+			Type:          payment.TypeCharge,
+			Source:        payment.SourceAutoCharge,
+			Amount:        inst.Amount,
+			Currency:      matched.Currency,
+			LoanID:        inst.LoanID,
+			InstallmentID: inst.ID,
+			OrderID:       &matched.ID,
+		}},
+		Currency:   matched.Currency,
+		CardToken:  matched.CardToken,
+		OffSession: true,
+		Apply: func(ctx context.Context, transactionID string) error {
+			return s.lmsClient.RecordPayment(ctx, lms.RecordPaymentRequest{
+				LoanID:        inst.LoanID,
+				InstallmentID: inst.ID,
+				Amount:        inst.Amount,
+				TransactionID: transactionID,
+			})
+		},
+		Revert: func(ctx context.Context, transactionID string) {
+			if err := s.lmsClient.ReversePayment(ctx, lms.ReversePaymentRequest{
+				LoanID:        inst.LoanID,
+				InstallmentID: inst.ID,
+				TransactionID: transactionID,
+			}); err != nil {
+				log.Error("failed to reverse LMS payment — needs reconciliation",
+					"transaction_id", transactionID,
+					"error", err,
+				)
+			}
+		},
 	})
+	result.TransactionID = txnID
+	if errors.Is(err, payment.ErrPending) {
+		log.Info("auto-charge awaiting PSP outcome", "transaction_id", txnID)
+		result.Outcome = OutcomePending
+		result.Reason = "PSP outcome pending"
+		return result
+	}
 	if err != nil {
-		log.Error("auto-charge failed", "error", err)
-		return
+		log.Error("auto-charge failed", "transaction_id", txnID, "error", err)
+		result.Outcome = OutcomeFailed
+		result.Reason = failureReason(err)
+		return result
 	}
 
-	if err := s.lmsClient.RecordPayment(ctx, lms.RecordPaymentRequest{
-		LoanID:        inst.LoanID,
-		InstallmentID: inst.ID,
-		Amount:        inst.Amount,
-		TransactionID: chargeResp.TransactionID,
-	}); err != nil {
-		log.Error("auto-charge succeeded but LMS update failed",
-			"transaction_id", chargeResp.TransactionID,
-			"error", err,
-		)
-		return
+	log.Info("auto-charge completed", "transaction_id", txnID)
+	result.Outcome = OutcomeCharged
+	return result
+}
+
+// failureReason turns a collection error into the short reason shown in the
+// auto-charge report.
+func failureReason(err error) string {
+	var ce *payment.CollectError
+	if !errors.As(err, &ce) {
+		return "could not write payment ledger"
+	}
+
+	var declined *psp.DeclineError
+	if errors.As(ce.Err, &declined) {
+		return declined.Error()
+	}
+	if errors.Is(ce.Err, payment.ErrActionRequired) {
+		return "customer authentication required; authorization voided"
 	}
 
-	log.Info("auto-charge completed", "transaction_id", chargeResp.TransactionID)
+	switch ce.Step {
+	case payment.StepAuthorize:
+		return "PSP authorization failed"
+	case payment.StepApply:
+		return "LMS rejected payment; authorization voided"
+	default:
+		return "PSP capture failed; LMS payment reversed"
+	}
 }
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/example/ppo/internal/client/lms"
+	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/payment"
+)
+
+// inFlight tracks installments that are currently being charged so that
+// overlapping runs (the cron job and a manual trigger) never charge the same
+// installment twice.
+type inFlight struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{ids: make(map[string]struct{})}
+}
+
+// acquire claims the installment, returning false if another run holds it.
+func (f *inFlight) acquire(installmentID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, busy := f.ids[installmentID]; busy {
+		return false
+	}
+	f.ids[installmentID] = struct{}{}
+	return true
+}
+
+func (f *inFlight) release(installmentID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.ids, installmentID)
+}
+
+func (f *inFlight) has(installmentID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, busy := f.ids[installmentID]
+	return busy
+}
+
+// ineligibleError explains why an overdue installment must not be charged.
+// It is reported as a skip rather than a failure.
+type ineligibleError struct {
+	reason string
+}
+
+func (e *ineligibleError) Error() string { return e.reason }
+
+func ineligible(format string, args ...any) error {
+	return &ineligibleError{reason: fmt.Sprintf(format, args...)}
+}
+
+// checkEligibility enforces the auto-charge rules: the order must still be
+// collectable, the loan must be active in LMS, the installment amount must
+// not exceed what the customer still owes, and no other charge for the
+// installment may be pending or authorized in the payment ledger. Any other
+// error means LMS or the ledger could not be consulted.
+func (s *Scheduler) checkEligibility(
+	ctx context.Context,
+	inst lms.Installment,
+	o *order.Order,
+) error {
+	if inst.Status != "" && inst.Status != lms.InstallmentStatusOverdue {
+		return ineligible("installment is %s, not overdue", inst.Status)
+	}
+	if inst.Amount <= 0 {
+		return ineligible("installment amount %d is not positive", inst.Amount)
+	}
+	if !o.Status.Chargeable() {
+		return ineligible("order is %s", o.Status)
+	}
+
+	pending, err := s.payments.List(ctx, payment.Filter{
+		Type:          payment.TypeCharge,
+		Statuses:      payment.InFlightStatuses,
+		InstallmentID: inst.ID,
+		Limit:         1,
+	})
+	if err != nil {
+		return fmt.Errorf("checking payment ledger: %w", err)
+	}
+	if len(pending) > 0 {
+		return ineligible("charge already in flight")
+	}
+
+	loan, err := s.lmsClient.GetLoan(ctx, inst.LoanID)
+	if err != nil {
+		return fmt.Errorf("fetching loan from LMS: %w", err)
+	}
+
+	if loan.Status != lms.LoanStatusActive {
+		return ineligible("loan is %s", loan.Status)
+	}
+	if inst.Amount > loan.Outstanding() {
+		return ineligible("installment amount %d exceeds outstanding balance %d",
+			inst.Amount, loan.Outstanding())
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+type Handler struct {
+	sched *Scheduler
+}
+
+func NewHandler(sched *Scheduler) *Handler {
+	return &Handler{sched: sched}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	jobs := rg.Group("/jobs")
+	jobs.POST("/auto-charge", h.RunAutoCharge)
+}
+
+// RunAutoCharge triggers the auto-charge job on demand. It defaults to a dry
+// run; callers must pass ?dry_run=false to actually charge cards.
+func (h *Handler) RunAutoCharge(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("dry_run must be a boolean", apperror.Field("dry_run", "boolean")).
+			Localized("request.invalid_dry_run"))
+		return
+	}
+
+	report, err := h.sched.RunAutoCharge(c.Request.Context(), dryRun)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, report)
+}
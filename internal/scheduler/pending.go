@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/internal/pspwebhook"
+)
+
+const (
+	// pendingGrace is how long a payment may stay in flight before the
+	// poller asks the PSP about it; webhooks normally arrive well within it.
+	pendingGrace = 10 * time.Minute
+	pendingBatch = 200
+)
+
+// resolvePendingPayments asks the PSP for the current state of payments that
+// are still in flight after pendingGrace and applies it exactly as a webhook
+// would, so a lost webhook never leaves a payment pending forever.
+func (s *Scheduler) resolvePendingPayments() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	rows, err := s.payments.List(ctx, payment.Filter{
+		Statuses: payment.InFlightStatuses,
+		To:       time.Now().UTC().Add(-pendingGrace),
+		Limit:    pendingBatch,
+	})
+	if err != nil {
+		s.logger.Error("failed to list in-flight payments", "error", err)
+		return
+	}
+
+	polled := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.PSPReference == "" {
+			// the PSP never answered the original request
+			s.logger.Warn("in-flight payment has no PSP reference — needs manual review", "payment_id", row.ID)
+			continue
+		}
+		if polled[row.PSPReference] {
+			continue
+		}
+		polled[row.PSPReference] = true
+
+		txn, err := s.pspClient.GetTransaction(ctx, row.PSPReference)
+		if err != nil {
+			s.logger.Error("failed to poll PSP transaction", "psp_reference", row.PSPReference, "error", err)
+			continue
+		}
+
+		if err := s.resolver.Apply(ctx, pspwebhook.Event{
+			ID:         "poll:" + txn.ID,
+			Type:       pspwebhook.EventType(row.Type),
+			Reference:  txn.ID,
+			Status:     txn.Status,
+			Amount:     txn.Amount,
+			OccurredAt: time.Now().UTC(),
+		}); err != nil {
+			s.logger.Error("failed to apply polled PSP status",
+				"psp_reference", txn.ID,
+				"status", txn.Status,
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import "time"
+
+type Outcome string
+
+const (
+	OutcomeCharged     Outcome = "charged"
+	OutcomeWouldCharge Outcome = "would_charge"
+	OutcomePending     Outcome = "pending"
+	OutcomeSkipped     Outcome = "skipped"
+	OutcomeFailed      Outcome = "failed"
+)
+
+// ChargeResult describes what the auto-charge job did (or, in dry-run mode,
+// would have done) for a single overdue installment.
+type ChargeResult struct {
+	LoanID        string  `json:"loan_id"`
+	InstallmentID string  `json:"installment_id"`
+	OrderID       string  `json:"order_id,omitempty"`
+	Amount        int64   `json:"amount"`
+	Currency      string  `json:"currency,omitempty"`
+	CardToken     string  `json:"card_token,omitempty"`
+	Outcome       Outcome `json:"outcome"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	Reason        string  `json:"reason,omitempty"`
+}
+
+type AutoChargeReport struct {
+	DryRun     bool            `json:"dry_run"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Results    []ChargeResult  `json:"results"`
+	Summary    map[Outcome]int `json:"summary"`
+}
+
+func (r *AutoChargeReport) add(res ChargeResult) {
+	r.Results = append(r.Results, res)
+	r.Summary[res.Outcome]++
+}
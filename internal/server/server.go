@@ -1,29 +1,46 @@
 package server
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
 
+	"github.com/example/ppo/internal/apidoc"
+	"github.com/example/ppo/internal/audit"
+	"github.com/example/ppo/internal/auth"
 	"github.com/example/ppo/internal/client/lms"
 	"github.com/example/ppo/internal/client/product"
 	"github.com/example/ppo/internal/client/psp"
 	"github.com/example/ppo/internal/config"
+	"github.com/example/ppo/internal/ledger"
 	mw "github.com/example/ppo/internal/middleware"
 	"github.com/example/ppo/internal/order"
+	"github.com/example/ppo/internal/payment"
 	"github.com/example/ppo/internal/postpurchase"
+	"github.com/example/ppo/internal/pspwebhook"
+	"github.com/example/ppo/internal/ratelimit"
+	"github.com/example/ppo/internal/reconciliation"
 	"github.com/example/ppo/internal/scheduler"
+	"github.com/example/ppo/internal/settlement"
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/envelope"
 )
 
+// Server holds the HTTP routers. AdminRouter is nil unless the admin API is
+// configured to run on its own port; it is then served only there.
 type Server struct {
-	Router    *gin.Engine
-	Scheduler *scheduler.Scheduler
+	Router      *gin.Engine
+	AdminRouter *gin.Engine
+	Scheduler   *scheduler.Scheduler
 }
 
-func New(cfg *config.Config, db *gorm.DB, logger *slog.Logger) *Server {
+func New(cfg *config.Config, db *gorm.DB, sealer *envelope.Sealer, verifier *auth.Verifier, logger *slog.Logger) (*Server, error) {
 	// --- external clients ---
 	var (
 		lmsClient  lms.Client
@@ -43,38 +60,140 @@ func New(cfg *config.Config, db *gorm.DB, logger *slog.Logger) *Server {
 		prodClient = product.NewHTTPClient(cfg.ProductBaseURL, httpClient)
 	}
 
+	if verifier == nil {
+		logger.Warn("AUTH_JWT_SECRET and AUTH_JWKS_FILE are not set — API requests will be rejected")
+	}
+	if cfg.PSPWebhookSecret == "" {
+		logger.Warn("PSP_WEBHOOK_SECRET is not set — PSP webhooks will be rejected")
+	}
+
+	limiter, err := newLimiter(cfg, db, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	// --- repositories ---
-	orderRepo := order.NewRepository(db)
+	orderRepo := order.NewRepository(db, sealer)
+	paymentRepo := payment.NewRepository(db)
+	reconciliationRepo := reconciliation.NewRepository(db)
+	settlementRepo := settlement.NewRepository(db)
+	ledgerRepo := ledger.NewRepository(db)
+	auditRepo := audit.NewRepository(db)
+	webhookRepo := pspwebhook.NewRepository(db)
 
 	// --- services ---
-	orderSvc := order.NewService(orderRepo, lmsClient, pspClient, prodClient, logger)
-	postPurchaseSvc := postpurchase.NewService(lmsClient, pspClient, logger)
+	ledgerSvc := ledger.NewService(ledgerRepo, logger)
+	auditSvc := audit.NewService(auditRepo, logger)
+	paymentSvc := payment.NewService(paymentRepo, ledgerSvc, logger)
+	orderSvc := order.NewService(orderRepo, lmsClient, pspClient, prodClient, paymentSvc, ledgerSvc, auditSvc, logger)
+	postPurchaseSvc := postpurchase.NewService(lmsClient, orderRepo, pspClient, paymentSvc, ledgerSvc, auditSvc, logger)
+	reconciliationSvc := reconciliation.NewService(reconciliationRepo, paymentSvc, lmsClient, pspClient, logger)
+	settlementSvc := settlement.NewService(settlementRepo, paymentSvc, logger)
+	webhookSvc := pspwebhook.NewService(webhookRepo, paymentSvc, orderSvc, postPurchaseSvc, lmsClient, pspClient, logger)
 
 	// --- handlers ---
 	orderHandler := order.NewHandler(orderSvc)
 	postPurchaseHandler := postpurchase.NewHandler(postPurchaseSvc)
+	paymentHandler := payment.NewHandler(paymentSvc)
+	reconciliationHandler := reconciliation.NewHandler(reconciliationSvc)
+	settlementHandler := settlement.NewHandler(settlementSvc)
+	ledgerHandler := ledger.NewHandler(ledgerSvc)
+	auditHandler := audit.NewHandler(auditSvc)
+	docsHandler := apidoc.NewHandler(apidoc.Build(apidoc.Info{
+		Title:   "PPO post-purchase API",
+		Version: "1.0.0",
+	}, "/api/v1", apidoc.PublicOperations))
+	webhookHandler := pspwebhook.NewHandler(webhookSvc, pspwebhook.NewVerifier(cfg.PSPWebhookSecret, cfg.PSPWebhookTolerance))
 
 	// --- gin router ---
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(requestLogger(logger))
-	r.Use(mw.ErrorHandler())
+	r, err := newRouter(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	// --- scheduler ---
+	sched := scheduler.New(lmsClient, pspClient, orderRepo, paymentSvc, reconciliationSvc, webhookSvc, cfg.AutoChargeDryRun, logger)
+	schedulerHandler := scheduler.NewHandler(sched)
 
-	v1 := r.Group("/api/v1")
+	docsHandler.RegisterRoutes(r)
+
+	v1 := r.Group("/api/v1", limiter.ByIP(), auth.Authenticate(verifier), limiter.ByUser())
 	orderHandler.RegisterRoutes(v1)
 	postPurchaseHandler.RegisterRoutes(v1)
 
-	// --- scheduler ---
-	sched := scheduler.New(lmsClient, pspClient, orderRepo, logger)
+	// authenticated by HMAC signature, not by the API's own auth
+	webhooks := r.Group("/webhooks")
+	webhookHandler.RegisterRoutes(webhooks)
+
+	// operators only: each resource needs its own read or write permission
+	var adminRouter *gin.Engine
+	adminRoot := r
+	if cfg.AdminPort != 0 {
+		if adminRouter, err = newRouter(cfg, logger); err != nil {
+			return nil, err
+		}
+		adminRoot = adminRouter
+	}
+	admin := adminRoot.Group("/admin/v1", adminAudit(auditSvc, logger), auth.AuthenticateOperator(verifier))
+	orderHandler.RegisterAdminRoutes(admin.Group("", auth.RequireAccess("orders")))
+	schedulerHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("jobs")))
+	paymentHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("payments")))
+	reconciliationHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("reconciliations")))
+	settlementHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("settlements")))
+	ledgerHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("ledger")))
+	auditHandler.RegisterRoutes(admin.Group("", auth.RequireAccess("audit")))
 
 	return &Server{
-		Router:    r,
-		Scheduler: sched,
+		Router:      r,
+		AdminRouter: adminRouter,
+		Scheduler:   sched,
+	}, nil
+}
+
+func newLimiter(cfg *config.Config, db *gorm.DB, logger *slog.Logger) (*ratelimit.Limiter, error) {
+	var store ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = ratelimit.NewPostgresStore(db)
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", cfg.RateLimitStore)
+	}
+
+	routes, err := ratelimit.ParseRoutes(cfg.RateLimitRoutes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing RATE_LIMIT_ROUTES: %w", err)
 	}
+
+	return ratelimit.NewLimiter(store, ratelimit.Rules{
+		PerIP:   ratelimit.PerMinute(cfg.RateLimitPerIP),
+		PerUser: ratelimit.PerMinute(cfg.RateLimitPerUser),
+		Routes:  routes,
+	}, logger), nil
+}
+
+func newRouter(cfg *config.Config, logger *slog.Logger) (*gin.Engine, error) {
+	// validation errors name fields the way clients send them
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(apperror.JSONFieldName)
+	}
+
+	r := gin.New()
+	// X-Forwarded-For is only believed from our own proxies; anyone else
+	// could rotate it to dodge the per-IP rate limit
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("parsing TRUSTED_PROXIES: %w", err)
+	}
+	r.Use(gin.Recovery())
+	r.Use(mw.RequestID())
+	r.Use(requestLogger(logger))
+	r.Use(mw.ErrorHandler())
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return r, nil
 }
 
 func requestLogger(logger *slog.Logger) gin.HandlerFunc {
@@ -86,6 +205,40 @@ func requestLogger(logger *slog.Logger) gin.HandlerFunc {
 			"path", c.Request.URL.Path,
 			"status", c.Writer.Status(),
 			"duration", time.Since(start).String(),
+			"request_id", mw.RequestIDFrom(c.Request.Context()),
 		)
 	}
 }
+
+// adminAudit records every admin API call in the audit trail, with the
+// operator who made it, including calls refused by authentication or
+// permissions. Errors are recorded as such: their response is written later
+// by the ErrorHandler.
+func adminAudit(auditSvc audit.Service, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		outcome := map[string]any{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+		}
+		if len(c.Errors) > 0 {
+			outcome["error"] = c.Errors.Last().Error()
+		} else {
+			outcome["status"] = c.Writer.Status()
+		}
+
+		attrs := []any{"method", c.Request.Method, "route", c.FullPath(), "path", c.Request.URL.Path}
+		if op, ok := auth.OperatorFrom(c.Request.Context()); ok {
+			attrs = append(attrs, "operator", op.Subject, "roles", op.Roles)
+		}
+		logger.Info("admin action", attrs...)
+
+		_ = auditSvc.Record(c.Request.Context(), audit.Entry{
+			Action:     audit.ActionAdminRequest,
+			EntityType: audit.EntityRoute,
+			EntityID:   c.FullPath(),
+			After:      outcome,
+		})
+	}
+}
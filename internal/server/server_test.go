@@ -0,0 +1,64 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/internal/config"
+	"github.com/example/ppo/internal/ratelimit"
+)
+
+func newLimitedRouter(t *testing.T, cfg *config.Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r, err := newRouter(cfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Rules{PerIP: ratelimit.PerMinute(1)}, logger)
+	r.GET("/limited", l.ByIP(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+// httptest requests come from 192.0.2.1; each carries a different
+// X-Forwarded-For, as a client dodging the per-IP limit would send.
+func forwardedCodes(r *gin.Engine, n int) []int {
+	codes := make([]int, n)
+	for i := range codes {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113."+strconv.Itoa(i+1))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		codes[i] = w.Code
+	}
+	return codes
+}
+
+func TestNewRouter_IgnoresSpoofedForwardedFor(t *testing.T) {
+	codes := forwardedCodes(newLimitedRouter(t, &config.Config{}), 2)
+	if codes[1] != http.StatusTooManyRequests {
+		t.Errorf("rotating X-Forwarded-For reset the per-IP bucket: got %v", codes)
+	}
+}
+
+func TestNewRouter_TrustsConfiguredProxies(t *testing.T) {
+	codes := forwardedCodes(newLimitedRouter(t, &config.Config{TrustedProxies: []string{"192.0.2.0/24"}}), 2)
+	if codes[0] != http.StatusNoContent || codes[1] != http.StatusNoContent {
+		t.Errorf("clients behind a trusted proxy should have their own buckets: got %v", codes)
+	}
+}
+
+func TestNewRouter_RejectsBadProxy(t *testing.T) {
+	_, err := newRouter(&config.Config{TrustedProxies: []string{"not-an-ip"}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err == nil {
+		t.Error("expected an error for an invalid trusted proxy")
+	}
+}
@@ -0,0 +1,54 @@
+package settlement
+
+import "github.com/google/uuid"
+
+type FileResponse struct {
+	ID             uuid.UUID     `json:"id"`
+	Filename       string        `json:"filename"`
+	RowCount       int           `json:"row_count"`
+	MatchedCount   int           `json:"matched_count"`
+	MismatchCount  int           `json:"mismatch_count"`
+	UnmatchedCount int           `json:"unmatched_count"`
+	ImportedAt     string        `json:"imported_at"`
+	Rows           []RowResponse `json:"rows,omitempty"`
+}
+
+type RowResponse struct {
+	Line          int         `json:"line"`
+	TransactionID string      `json:"transaction_id"`
+	Amount        int64       `json:"amount"`
+	Currency      string      `json:"currency"`
+	Fee           int64       `json:"fee"`
+	Status        string      `json:"status"`
+	SettledDate   string      `json:"settled_date"`
+	MatchStatus   MatchStatus `json:"match_status"`
+	PaymentType   string      `json:"payment_type,omitempty"`
+	Detail        string      `json:"detail,omitempty"`
+}
+
+func ToResponse(f *File) FileResponse {
+	resp := FileResponse{
+		ID:             f.ID,
+		Filename:       f.Filename,
+		RowCount:       f.RowCount,
+		MatchedCount:   f.MatchedCount,
+		MismatchCount:  f.MismatchCount,
+		UnmatchedCount: f.UnmatchedCount,
+		ImportedAt:     f.ImportedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	for _, r := range f.Rows {
+		resp.Rows = append(resp.Rows, RowResponse{
+			Line:          r.Line,
+			TransactionID: r.TransactionID,
+			Amount:        r.Amount,
+			Currency:      r.Currency,
+			Fee:           r.Fee,
+			Status:        r.Status,
+			SettledDate:   r.SettledDate.Format(dateLayout),
+			MatchStatus:   r.MatchStatus,
+			PaymentType:   r.PaymentType,
+			Detail:        r.Detail,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,105 @@
+package settlement
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/pkg/apperror"
+	"github.com/example/ppo/pkg/response"
+)
+
+// maxUploadSize bounds a settlement upload; daily files are well below this.
+const maxUploadSize = 32 << 20
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	settlements := rg.Group("/settlements")
+	settlements.POST("", h.ImportFile)
+	settlements.GET("", h.ListFiles)
+	settlements.GET("/:id", h.GetFile)
+}
+
+// ImportFile accepts the CSV either as a multipart "file" field or as the raw
+// request body (Content-Type: text/csv, name taken from ?filename=).
+func (h *Handler) ImportFile(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSize)
+
+	filename := c.DefaultQuery("filename", "upload.csv")
+	var data []byte
+
+	if fh, err := c.FormFile("file"); err == nil {
+		filename = fh.Filename
+		f, err := fh.Open()
+		if err != nil {
+			_ = c.Error(apperror.NewInvalidRequest("could not open uploaded file").Localized("settlement.upload_unopenable"))
+			return
+		}
+		defer f.Close()
+		data, err = io.ReadAll(f)
+		if err != nil {
+			_ = c.Error(apperror.NewInvalidRequest("could not read uploaded file").Localized("settlement.upload_unreadable"))
+			return
+		}
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			_ = c.Error(apperror.NewInvalidRequest("could not read request body").Localized("request.body_unreadable"))
+			return
+		}
+	}
+
+	if len(data) == 0 {
+		_ = c.Error(apperror.NewInvalidRequest("settlement file is required", apperror.Field("file", "required")).
+			Localized("settlement.file_required"))
+		return
+	}
+
+	f, err := h.svc.Import(c.Request.Context(), filename, data)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.Created(c, ToResponse(f))
+}
+
+func (h *Handler) ListFiles(c *gin.Context) {
+	files, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	out := make([]FileResponse, len(files))
+	for i := range files {
+		out[i] = ToResponse(&files[i])
+	}
+	response.OK(c, out)
+}
+
+func (h *Handler) GetFile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperror.NewInvalidRequest("invalid settlement file id", apperror.Field("id", "uuid")).
+			Localized("settlement.invalid_id"))
+		return
+	}
+
+	f, err := h.svc.Get(c.Request.Context(), id, MatchStatus(c.Query("match_status")))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	response.OK(c, ToResponse(f))
+}
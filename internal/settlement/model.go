@@ -0,0 +1,45 @@
+package settlement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MatchStatus string
+
+const (
+	MatchStatusMatched   MatchStatus = "matched"
+	MatchStatusMismatch  MatchStatus = "mismatch"
+	MatchStatusUnmatched MatchStatus = "unmatched"
+)
+
+type File struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Filename       string    `gorm:"type:varchar(255);not null"`
+	Checksum       string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	RowCount       int       `gorm:"not null;default:0"`
+	MatchedCount   int       `gorm:"not null;default:0"`
+	MismatchCount  int       `gorm:"not null;default:0"`
+	UnmatchedCount int       `gorm:"not null;default:0"`
+	Rows           []Row     `gorm:"foreignKey:FileID"`
+	ImportedAt     time.Time `gorm:"not null"`
+}
+
+type Row struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	FileID        uuid.UUID   `gorm:"type:uuid;not null;index"`
+	Line          int         `gorm:"not null"`
+	TransactionID string      `gorm:"type:varchar(128);not null;index"`
+	Amount        int64       `gorm:"not null"`
+	Currency      string      `gorm:"type:varchar(3);not null"`
+	Fee           int64       `gorm:"not null;default:0"`
+	Status        string      `gorm:"type:varchar(32);not null"`
+	SettledDate   time.Time   `gorm:"type:date;not null"`
+	MatchStatus   MatchStatus `gorm:"type:varchar(20);not null"`
+	PaymentType   string      `gorm:"type:varchar(20);not null;default:''"`
+	Detail        string      `gorm:"type:text;not null;default:''"`
+}
+
+func (File) TableName() string { return "settlement_files" }
+func (Row) TableName() string  { return "settlement_rows" }
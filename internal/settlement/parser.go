@@ -0,0 +1,108 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var requiredColumns = []string{"transaction_id", "amount", "currency", "fee", "status", "settled_date"}
+
+// Record is one parsed line of a PSP settlement file. Amounts are in minor
+// units, like everywhere else in the service.
+type Record struct {
+	Line          int
+	TransactionID string
+	Amount        int64
+	Currency      string
+	Fee           int64
+	Status        string
+	SettledDate   time.Time
+}
+
+// Parse reads a settlement CSV. Columns are located by header name (case
+// insensitive), so the PSP may reorder them or add extra ones. Any malformed
+// line fails the whole file: a partially imported settlement is worse than
+// none.
+func Parse(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("settlement file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("missing required column %q", name)
+		}
+	}
+
+	var records []Record
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading settlement file: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rec, err := parseRecord(fields, cols, line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func parseRecord(fields []string, cols map[string]int, line int) (Record, error) {
+	get := func(name string) string { return strings.TrimSpace(fields[cols[name]]) }
+
+	rec := Record{
+		Line:          line,
+		TransactionID: get("transaction_id"),
+		Currency:      strings.ToUpper(get("currency")),
+		Status:        strings.ToLower(get("status")),
+	}
+
+	if rec.TransactionID == "" {
+		return rec, errors.New("transaction_id is empty")
+	}
+	if len(rec.Currency) != 3 {
+		return rec, fmt.Errorf("invalid currency %q", rec.Currency)
+	}
+	if rec.Status == "" {
+		return rec, errors.New("status is empty")
+	}
+
+	var err error
+	if rec.Amount, err = strconv.ParseInt(get("amount"), 10, 64); err != nil {
+		return rec, fmt.Errorf("invalid amount %q", get("amount"))
+	}
+	if rec.Fee, err = strconv.ParseInt(get("fee"), 10, 64); err != nil {
+		return rec, fmt.Errorf("invalid fee %q", get("fee"))
+	}
+	if rec.SettledDate, err = time.Parse(dateLayout, get("settled_date")); err != nil {
+		return rec, fmt.Errorf("invalid settled_date %q", get("settled_date"))
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,74 @@
+package settlement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_Success(t *testing.T) {
+	input := `transaction_id,amount,currency,fee,status,settled_date
+txn-001,15000,sar,225,settled,2026-03-01
+ref-002,-5000,SAR,0,refunded,2026-03-01
+`
+	records, err := Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].TransactionID != "txn-001" || records[0].Amount != 15000 || records[0].Fee != 225 {
+		t.Errorf("unexpected first record: %+v", records[0])
+	}
+	if records[0].Currency != "SAR" {
+		t.Errorf("expected currency to be upper-cased, got %q", records[0].Currency)
+	}
+	if records[1].Line != 3 {
+		t.Errorf("expected second record on line 3, got %d", records[1].Line)
+	}
+}
+
+func TestParse_ColumnsByHeaderName(t *testing.T) {
+	input := `Settled_Date,Status,Fee,Currency,Amount,Transaction_ID,Batch
+2026-03-01,settled,100,SAR,20000,txn-009,b-1
+`
+	records, err := Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records[0].TransactionID != "txn-009" || records[0].Amount != 20000 {
+		t.Errorf("columns not mapped by header: %+v", records[0])
+	}
+}
+
+func TestParse_MissingColumn(t *testing.T) {
+	input := `transaction_id,amount,currency,status,settled_date
+txn-001,15000,SAR,settled,2026-03-01
+`
+	if _, err := Parse(strings.NewReader(input)); err == nil {
+		t.Fatal("expected error for missing fee column")
+	}
+}
+
+func TestParse_InvalidAmountReportsLine(t *testing.T) {
+	input := `transaction_id,amount,currency,fee,status,settled_date
+txn-001,15000,SAR,0,settled,2026-03-01
+txn-002,15.00,SAR,0,settled,2026-03-01
+`
+	_, err := Parse(strings.NewReader(input))
+
+	if err == nil {
+		t.Fatal("expected error for decimal amount")
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected error to mention line 3, got %v", err)
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	if _, err := Parse(strings.NewReader("")); err == nil {
+		t.Fatal("expected error for empty file")
+	}
+}
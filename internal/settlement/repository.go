@@ -0,0 +1,70 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+type Repository interface {
+	// Create stores the file and all of its rows atomically.
+	Create(ctx context.Context, f *File) error
+	ExistsByChecksum(ctx context.Context, checksum string) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID, matchStatus MatchStatus) (*File, error)
+	List(ctx context.Context, limit int) ([]File, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, f *File) error {
+	if err := r.db.WithContext(ctx).Create(f).Error; err != nil {
+		return apperror.NewInternal("storing settlement file", err)
+	}
+	return nil
+}
+
+func (r *repository) ExistsByChecksum(ctx context.Context, checksum string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&File{}).Where("checksum = ?", checksum).Count(&count).Error; err != nil {
+		return false, apperror.NewInternal("checking settlement file checksum", err)
+	}
+	return count > 0, nil
+}
+
+// GetByID loads a file with its rows, optionally only those with the given
+// match status.
+func (r *repository) GetByID(ctx context.Context, id uuid.UUID, matchStatus MatchStatus) (*File, error) {
+	var f File
+	err := r.db.WithContext(ctx).Preload("Rows", func(db *gorm.DB) *gorm.DB {
+		if matchStatus != "" {
+			db = db.Where("match_status = ?", matchStatus)
+		}
+		return db.Order("line")
+	}).First(&f, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NewNotFound(fmt.Sprintf("settlement file %s not found", id)).Localized("settlement.not_found", "file", id)
+	}
+	if err != nil {
+		return nil, apperror.NewInternal("fetching settlement file", err)
+	}
+	return &f, nil
+}
+
+func (r *repository) List(ctx context.Context, limit int) ([]File, error) {
+	var files []File
+	if err := r.db.WithContext(ctx).Order("imported_at DESC").Limit(limit).Find(&files).Error; err != nil {
+		return nil, apperror.NewInternal("listing settlement files", err)
+	}
+	return files, nil
+}
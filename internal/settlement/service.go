@@ -0,0 +1,159 @@
+package settlement
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/ppo/internal/payment"
+	"github.com/example/ppo/pkg/apperror"
+)
+
+const listFilesLimit = 50
+
+type Service interface {
+	// Import parses a PSP settlement file, matches every line against our
+	// recorded charges and refunds, and stores the outcome for finance.
+	// Importing the same file twice is rejected.
+	Import(ctx context.Context, filename string, data []byte) (*File, error)
+	Get(ctx context.Context, id uuid.UUID, matchStatus MatchStatus) (*File, error)
+	List(ctx context.Context) ([]File, error)
+}
+
+type service struct {
+	repo     Repository
+	payments payment.Service
+	logger   *slog.Logger
+}
+
+func NewService(repo Repository, payments payment.Service, logger *slog.Logger) Service {
+	return &service{repo: repo, payments: payments, logger: logger}
+}
+
+func (s *service) Import(ctx context.Context, filename string, data []byte) (*File, error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	exists, err := s.repo.ExistsByChecksum(ctx, checksum)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, apperror.NewConflict(fmt.Sprintf("settlement file %s was already imported", filename)).
+			Localized("settlement.already_imported", "file", filename)
+	}
+
+	records, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperror.NewValidation(fmt.Sprintf("invalid settlement file: %v", err)).
+			Localized("settlement.invalid_file", "reason", err)
+	}
+
+	f := &File{
+		Filename:   filename,
+		Checksum:   checksum,
+		RowCount:   len(records),
+		Rows:       make([]Row, 0, len(records)),
+		ImportedAt: time.Now().UTC(),
+	}
+
+	for _, rec := range records {
+		row, err := s.match(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+
+		switch row.MatchStatus {
+		case MatchStatusMatched:
+			f.MatchedCount++
+		case MatchStatusMismatch:
+			f.MismatchCount++
+		default:
+			f.UnmatchedCount++
+		}
+		f.Rows = append(f.Rows, row)
+	}
+
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("settlement file imported",
+		"file_id", f.ID,
+		"filename", filename,
+		"rows", f.RowCount,
+		"matched", f.MatchedCount,
+		"mismatched", f.MismatchCount,
+		"unmatched", f.UnmatchedCount,
+	)
+	return f, nil
+}
+
+// match looks the settlement line up by PSP reference. Refunds may be
+// reported with a negative amount, so only magnitudes are compared.
+func (s *service) match(ctx context.Context, rec Record) (Row, error) {
+	row := Row{
+		Line:          rec.Line,
+		TransactionID: rec.TransactionID,
+		Amount:        rec.Amount,
+		Currency:      rec.Currency,
+		Fee:           rec.Fee,
+		Status:        rec.Status,
+		SettledDate:   rec.SettledDate,
+	}
+
+	payments, err := s.payments.List(ctx, payment.Filter{
+		PSPReference: rec.TransactionID,
+		Status:       payment.StatusSucceeded,
+	})
+	if err != nil {
+		return row, err
+	}
+
+	if len(payments) == 0 {
+		row.MatchStatus = MatchStatusUnmatched
+		row.Detail = "no succeeded payment with this PSP reference"
+		return row, nil
+	}
+
+	// a batch checkout is several ledger rows for one PSP charge
+	var total int64
+	for _, p := range payments {
+		total += p.Amount
+	}
+	row.PaymentType = string(payments[0].Type)
+
+	switch {
+	case !strings.EqualFold(payments[0].Currency, rec.Currency):
+		row.MatchStatus = MatchStatusMismatch
+		row.Detail = fmt.Sprintf("currency %s differs from recorded %s", rec.Currency, payments[0].Currency)
+	case abs(rec.Amount) != total:
+		row.MatchStatus = MatchStatusMismatch
+		row.Detail = fmt.Sprintf("amount %d differs from recorded %d", abs(rec.Amount), total)
+	default:
+		row.MatchStatus = MatchStatusMatched
+	}
+	return row, nil
+}
+
+func (s *service) Get(ctx context.Context, id uuid.UUID, matchStatus MatchStatus) (*File, error) {
+	return s.repo.GetByID(ctx, id, matchStatus)
+}
+
+func (s *service) List(ctx context.Context) ([]File, error) {
+	return s.repo.List(ctx, listFilesLimit)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -1,6 +1,11 @@
 package apperror
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/ppo/pkg/i18n"
+)
 
 type Kind int
 
@@ -10,12 +15,54 @@ const (
 	KindConflict
 	KindUpstream
 	KindInternal
+	KindDeclined
+	KindUnauthorized
+	KindForbidden
+	KindRateLimited
+	// KindInvalidRequest is a request body or query that failed to bind:
+	// malformed JSON or a field breaking its binding rules.
+	KindInvalidRequest
 )
 
+// FieldError pins a validation failure to one request field. Field is the
+// path as the client sent it, e.g. "items[0].quantity".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Key and Args render Message in the client's language.
+	Key  string            `json:"-"`
+	Args map[string]string `json:"-"`
+}
+
+// Field builds the FieldError for a broken rule, its message taken from the
+// "field.<rule>" catalog entry. args are name/value pairs filling the
+// message's placeholders.
+func Field(field, rule string, args ...any) FieldError {
+	return fieldWithKey(field, rule, "field."+rule, args...)
+}
+
+func fieldWithKey(field, rule, key string, args ...any) FieldError {
+	fe := FieldError{Field: field, Rule: rule, Key: key, Args: argMap(args)}
+	fe.Message, _ = i18n.Message(i18n.Default, key, fe.Args)
+	return fe
+}
+
 type Error struct {
 	Kind    Kind
 	Message string
 	Err     error
+	// DeclineCode is the PSP's reason for a KindDeclined error.
+	DeclineCode string
+	// RetryAfter tells a KindRateLimited caller when to try again.
+	RetryAfter time.Duration
+	// Fields lists the offending fields of a KindValidation or
+	// KindInvalidRequest error, when known.
+	Fields []FieldError
+	// MessageKey and MessageArgs render Message in the client's language;
+	// see Localized.
+	MessageKey  string
+	MessageArgs map[string]string
 }
 
 func (e *Error) Error() string {
@@ -27,12 +74,32 @@ func (e *Error) Error() string {
 
 func (e *Error) Unwrap() error { return e.Err }
 
+// Localized sets the catalog key the client-facing message is rendered from.
+// args are name/value pairs filling the message's placeholders. Message stays
+// as given and is what gets logged.
+func (e *Error) Localized(key string, args ...any) *Error {
+	e.MessageKey = key
+	e.MessageArgs = argMap(args)
+	return e
+}
+
+func argMap(args []any) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		m[fmt.Sprint(args[i])] = fmt.Sprint(args[i+1])
+	}
+	return m
+}
+
 func NewNotFound(msg string) *Error {
 	return &Error{Kind: KindNotFound, Message: msg}
 }
 
-func NewValidation(msg string) *Error {
-	return &Error{Kind: KindValidation, Message: msg}
+func NewValidation(msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: msg, Fields: fields}
 }
 
 func NewConflict(msg string) *Error {
@@ -46,3 +113,23 @@ func NewUpstream(msg string, err error) *Error {
 func NewInternal(msg string, err error) *Error {
 	return &Error{Kind: KindInternal, Message: msg, Err: err}
 }
+
+func NewDeclined(msg, declineCode string) *Error {
+	return &Error{Kind: KindDeclined, Message: msg, DeclineCode: declineCode}
+}
+
+func NewUnauthorized(msg string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: msg}
+}
+
+func NewForbidden(msg string) *Error {
+	return &Error{Kind: KindForbidden, Message: msg}
+}
+
+func NewRateLimited(msg string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Message: msg, RetryAfter: retryAfter}
+}
+
+func NewInvalidRequest(msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindInvalidRequest, Message: msg, Fields: fields}
+}
@@ -0,0 +1,133 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NewBindingError turns the error from binding a request body or query into
+// a KindInvalidRequest error whose Fields name each offending field by its
+// JSON path. Field paths only use JSON names once JSONFieldName is
+// registered with the validator.
+func NewBindingError(err error) *Error {
+	var (
+		verrs   validator.ValidationErrors
+		typeErr *json.UnmarshalTypeError
+		synErr  *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]FieldError, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = fieldError(fe)
+		}
+		return invalidFields(err, fields)
+	case errors.As(err, &typeErr):
+		field := decodePath(typeErr.Field)
+		if field == "" {
+			field = "body"
+		}
+		return invalidFields(err, []FieldError{
+			fieldWithKey(field, "type", "field.type."+typeName(typeErr.Type), "value", typeErr.Value),
+		})
+	case errors.As(err, &synErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return (&Error{Kind: KindInvalidRequest, Message: "request body is not valid JSON", Err: err}).
+			Localized("request.malformed")
+	case errors.Is(err, io.EOF):
+		return (&Error{Kind: KindInvalidRequest, Message: "request body is required", Err: err}).
+			Localized("request.body_required")
+	default:
+		return &Error{Kind: KindInvalidRequest, Message: err.Error(), Err: err}
+	}
+}
+
+func invalidFields(err error, fields []FieldError) *Error {
+	return (&Error{Kind: KindInvalidRequest, Message: "request has invalid fields", Fields: fields, Err: err}).
+		Localized("request.invalid_fields")
+}
+
+// JSONFieldName names a struct field by its json tag, falling back to its
+// form tag for query structs. Register it with the request validator so
+// validation errors carry the names clients send.
+func JSONFieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func fieldError(fe validator.FieldError) FieldError {
+	// the namespace starts with the request struct's own name
+	field := fe.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	key := "field." + fe.Tag()
+	switch fe.Tag() {
+	case "required", "gt", "gte", "lt", "lte", "oneof", "uuid", "datetime":
+	case "len", "min", "max":
+		// lengths count characters of a string, items of a collection
+		unit := "chars"
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			unit = "items"
+		}
+		if fe.Param() == "1" {
+			unit += "_one"
+		}
+		key += "." + unit
+	default:
+		key = "field.invalid"
+	}
+	param := strings.ReplaceAll(fe.Param(), " ", ", ")
+	return fieldWithKey(field, fe.Tag(), key, "param", param, "rule", fe.Tag())
+}
+
+// decodePath rewrites encoding/json's "items.0.quantity" in the validator's
+// "items[0].quantity" form.
+func decodePath(path string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// typeName names the JSON type a Go type decodes from.
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,143 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type bindItem struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+}
+
+type bindRequest struct {
+	Currency string     `json:"currency" binding:"required,len=3"`
+	Items    []bindItem `json:"items" binding:"required,min=1,dive"`
+	Kind     string     `form:"kind" binding:"omitempty,oneof=charge refund"`
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(JSONFieldName)
+	return v
+}
+
+// wire keeps only what a FieldError puts on the wire.
+func wire(fields []FieldError) []FieldError {
+	if fields == nil {
+		return nil
+	}
+	out := make([]FieldError, len(fields))
+	for i, fe := range fields {
+		out[i] = FieldError{Field: fe.Field, Rule: fe.Rule, Message: fe.Message}
+	}
+	return out
+}
+
+func TestNewBindingErrorValidation(t *testing.T) {
+	v := newValidator()
+
+	tests := []struct {
+		name string
+		req  bindRequest
+		want []FieldError
+	}{
+		{
+			name: "top level",
+			req:  bindRequest{Currency: "SA", Items: []bindItem{{ProductID: "p", Quantity: 1}}},
+			want: []FieldError{{Field: "currency", Rule: "len", Message: "must be exactly 3 characters long"}},
+		},
+		{
+			name: "empty slice",
+			req:  bindRequest{Currency: "SAR", Items: []bindItem{}},
+			want: []FieldError{{Field: "items", Rule: "min", Message: "must have at least 1 item"}},
+		},
+		{
+			name: "nested",
+			req: bindRequest{Currency: "SAR", Items: []bindItem{
+				{ProductID: "p", Quantity: 1},
+				{Quantity: -1},
+			}},
+			want: []FieldError{
+				{Field: "items[1].product_id", Rule: "required", Message: "is required"},
+				{Field: "items[1].quantity", Rule: "gt", Message: "must be greater than 0"},
+			},
+		},
+		{
+			name: "form tag",
+			req:  bindRequest{Currency: "SAR", Items: []bindItem{{ProductID: "p", Quantity: 1}}, Kind: "x"},
+			want: []FieldError{{Field: "kind", Rule: "oneof", Message: "must be one of: charge, refund"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(tt.req)
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+			got := NewBindingError(err)
+			if got.Kind != KindInvalidRequest {
+				t.Errorf("kind = %v, want KindInvalidRequest", got.Kind)
+			}
+			if !reflect.DeepEqual(wire(got.Fields), tt.want) {
+				t.Errorf("fields = %+v, want %+v", got.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBindingErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+		fields  []FieldError
+	}{
+		{
+			name:    "wrong type",
+			body:    `{"currency":"SAR","items":[{"product_id":"p","quantity":"two"}]}`,
+			message: "request has invalid fields",
+			fields:  []FieldError{{Field: "items[0].quantity", Rule: "type", Message: "must be an integer, not string"}},
+		},
+		{
+			name:    "malformed",
+			body:    `{"currency":`,
+			message: "request body is not valid JSON",
+		},
+		{
+			name:    "syntax",
+			body:    `{"currency" "SAR"}`,
+			message: "request body is not valid JSON",
+		},
+		{
+			name:    "empty",
+			body:    ``,
+			message: "request body is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req bindRequest
+			err := json.NewDecoder(strings.NewReader(tt.body)).Decode(&req)
+			if err == nil {
+				t.Fatal("expected a decoding error")
+			}
+			got := NewBindingError(err)
+			if got.Message != tt.message {
+				t.Errorf("message = %q, want %q", got.Message, tt.message)
+			}
+			if !reflect.DeepEqual(wire(got.Fields), tt.fields) {
+				t.Errorf("fields = %+v, want %+v", got.Fields, tt.fields)
+			}
+			if !errors.Is(got, err) {
+				t.Error("binding error does not wrap the original error")
+			}
+		})
+	}
+}
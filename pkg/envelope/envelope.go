@@ -0,0 +1,121 @@
+// Package envelope encrypts short secrets such as card tokens for storage.
+// Every value gets its own random data key; the data key is wrapped by a
+// key-encryption key from a KeyProvider and stored next to the ciphertext,
+// so rotating the KEK only re-wraps, and old values name the KEK they need.
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks a sealed value. The full format is
+// "enc:v1:<key id>:<base64 wrapped data key>:<base64 nonce+ciphertext>".
+const prefix = "enc:v1:"
+
+var ErrMalformed = errors.New("malformed sealed value")
+
+type Sealer struct {
+	keys KeyProvider
+}
+
+func New(keys KeyProvider) *Sealer {
+	return &Sealer{keys: keys}
+}
+
+// IsSealed reports whether v was produced by Seal. Values written before
+// encryption was introduced are plaintext.
+func IsSealed(v string) bool {
+	return strings.HasPrefix(v, prefix)
+}
+
+// Seal encrypts plaintext under a fresh data key wrapped with the current KEK.
+func (s *Sealer) Seal(ctx context.Context, plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("generating data key: %w", err)
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	keyID := s.keys.CurrentKeyID()
+	wrapped, err := s.keys.Wrap(ctx, keyID, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("wrapping data key: %w", err)
+	}
+
+	return prefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Open decrypts a sealed value. A plaintext value is returned unchanged so
+// rows written before encryption keep working until they are rotated.
+func (s *Sealer) Open(ctx context.Context, v string) (string, error) {
+	if !IsSealed(v) {
+		return v, nil
+	}
+
+	keyID, wrapped, ciphertext, err := parse(v)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := s.keys.Unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("unwrapping data key: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("decrypting value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether v is plaintext or sealed under a KEK other
+// than the current one.
+func (s *Sealer) NeedsRotation(v string) bool {
+	if !IsSealed(v) {
+		return true
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(v, prefix), ":")
+	return keyID != s.keys.CurrentKeyID()
+}
+
+// Reseal opens v and seals it again under the current KEK.
+func (s *Sealer) Reseal(ctx context.Context, v string) (string, error) {
+	plaintext, err := s.Open(ctx, v)
+	if err != nil {
+		return "", err
+	}
+	return s.Seal(ctx, plaintext)
+}
+
+func parse(v string) (keyID string, wrapped, ciphertext []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(v, prefix), ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", nil, nil, ErrMalformed
+	}
+	if wrapped, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	if ciphertext, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[0], wrapped, ciphertext, nil
+}
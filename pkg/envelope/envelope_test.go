@@ -0,0 +1,173 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKeys(t *testing.T, current string, ids ...string) *LocalKeyProvider {
+	t.Helper()
+	keys := map[string][]byte{}
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, 32)
+	}
+	p, err := NewLocalKeyProvider(current, keys)
+	if err != nil {
+		t.Fatalf("building key provider: %v", err)
+	}
+	return p
+}
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := New(testKeys(t, "k1", "k1"))
+
+	sealed, err := s.Seal(ctx, "tok_visa_4242")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatalf("expected sealed value, got %q", sealed)
+	}
+	if strings.Contains(sealed, "tok_visa_4242") {
+		t.Fatal("sealed value contains the plaintext")
+	}
+
+	again, _ := s.Seal(ctx, "tok_visa_4242")
+	if again == sealed {
+		t.Error("expected a fresh data key and nonce for every seal")
+	}
+
+	plain, err := s.Open(ctx, sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain != "tok_visa_4242" {
+		t.Errorf("expected tok_visa_4242, got %q", plain)
+	}
+}
+
+func TestOpen_PlaintextPassesThrough(t *testing.T) {
+	s := New(testKeys(t, "k1", "k1"))
+
+	plain, err := s.Open(context.Background(), "tok_legacy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain != "tok_legacy" {
+		t.Errorf("expected tok_legacy, got %q", plain)
+	}
+}
+
+func TestOpen_Tampered(t *testing.T) {
+	ctx := context.Background()
+	s := New(testKeys(t, "k1", "k1"))
+	sealed, _ := s.Seal(ctx, "tok_visa_4242")
+
+	if _, err := s.Open(ctx, sealed[:len(sealed)-4]+"AAAA"); err == nil {
+		t.Error("expected error for tampered ciphertext")
+	}
+	if _, err := s.Open(ctx, "enc:v1:k1:only-two-parts"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	old := New(testKeys(t, "k1", "k1", "k2"))
+	sealed, _ := old.Seal(ctx, "tok_visa_4242")
+
+	rotated := New(testKeys(t, "k2", "k1", "k2"))
+	if !rotated.NeedsRotation(sealed) {
+		t.Fatal("expected value sealed under k1 to need rotation")
+	}
+	if !rotated.NeedsRotation("tok_legacy") {
+		t.Fatal("expected plaintext to need rotation")
+	}
+
+	resealed, err := rotated.Reseal(ctx, sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated.NeedsRotation(resealed) {
+		t.Error("expected resealed value to use the current key")
+	}
+	if !strings.HasPrefix(resealed, "enc:v1:k2:") {
+		t.Errorf("expected value sealed under k2, got %q", resealed)
+	}
+
+	plain, err := rotated.Open(ctx, resealed)
+	if err != nil || plain != "tok_visa_4242" {
+		t.Errorf("expected tok_visa_4242, got %q (err %v)", plain, err)
+	}
+
+	// once k1 is dropped, values still under it can no longer be opened
+	dropped := New(testKeys(t, "k2", "k2"))
+	if _, err := dropped.Open(ctx, sealed); err == nil {
+		t.Error("expected error opening a value whose key was removed")
+	}
+}
+
+func TestNewLocalKeyProvider_Invalid(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	tests := []struct {
+		name    string
+		current string
+		keys    map[string][]byte
+	}{
+		{name: "current missing", current: "k2", keys: map[string][]byte{"k1": key}},
+		{name: "short key", current: "k1", keys: map[string][]byte{"k1": key[:16]}},
+		{name: "colon in id", current: "k:1", keys: map[string][]byte{"k:1": key}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLocalKeyProvider(tt.current, tt.keys); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	keys, err := ParseKeys("k1:" + k1 + ", k2:" + k2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys["k2"][0] != 2 {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+
+	if _, err := ParseKeys(""); err == nil {
+		t.Error("expected error for empty spec")
+	}
+	if _, err := ParseKeys("k1"); err == nil {
+		t.Error("expected error for entry without key")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(`{"current":"k1","keys":{"k1":"`+k1+`"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	current, keys, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current != "k1" || len(keys["k1"]) != 32 {
+		t.Errorf("unexpected result: current=%q keys=%v", current, keys)
+	}
+}
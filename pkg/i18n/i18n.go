@@ -0,0 +1,88 @@
+// Package i18n holds the message catalogs API errors are rendered from and
+// picks a catalog from a request's Accept-Language header.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	English = "en"
+	Arabic  = "ar"
+
+	// Default is served when a request asks for no supported language.
+	Default = English
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs maps a language to its messages, keyed by message key. Messages
+// name their arguments in braces, e.g. "order {order} not found".
+var catalogs = mustLoad(English, Arabic)
+
+func mustLoad(langs ...string) map[string]map[string]string {
+	out := make(map[string]map[string]string, len(langs))
+	for _, lang := range langs {
+		raw, err := locales.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: reading %s catalog: %v", lang, err))
+		}
+		var msgs map[string]string
+		if err := json.Unmarshal(raw, &msgs); err != nil {
+			panic(fmt.Sprintf("i18n: parsing %s catalog: %v", lang, err))
+		}
+		out[lang] = msgs
+	}
+	return out
+}
+
+// Supported reports whether lang has a catalog.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Message renders key in lang, filling each {name} from args. It reports
+// false when lang has no such key.
+func Message(lang, key string, args map[string]string) (string, bool) {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return msg, true
+	}
+	pairs := make([]string, 0, 2*len(args))
+	for name, v := range args {
+		pairs = append(pairs, "{"+name+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg), true
+}
+
+// Negotiate picks the supported language the Accept-Language header prefers
+// most, or Default. Regional variants match their base language, so "ar-SA"
+// selects Arabic.
+func Negotiate(header string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if Supported(base) && q > bestQ {
+			best, bestQ = base, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"ar", Arabic},
+		{"ar-SA", Arabic},
+		{"AR-sa,en;q=0.8", Arabic},
+		{"en-US,ar;q=0.9", English},
+		{"fr,ar;q=0.5,en;q=0.4", Arabic},
+		{"fr-FR", English},
+		{"ar;q=0", English},
+		{"*", English},
+		{"ar;q=bogus,en", English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Negotiate(tt.header); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	got, ok := Message(English, "order.already_closed", map[string]string{"order": "o-1", "status": "refunded"})
+	if !ok || got != "order o-1 is already refunded" {
+		t.Errorf("English message = %q, %v", got, ok)
+	}
+
+	got, ok = Message(Arabic, "loan.not_found", map[string]string{"loan": "L-7"})
+	if !ok || !strings.Contains(got, "L-7") || strings.Contains(got, "{loan}") {
+		t.Errorf("Arabic message = %q, %v", got, ok)
+	}
+
+	if _, ok := Message(English, "no.such.key", nil); ok {
+		t.Error("unknown key reported as found")
+	}
+	if _, ok := Message("fr", "loan.not_found", nil); ok {
+		t.Error("unsupported language reported as found")
+	}
+}
+
+var placeholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// every catalog must carry the same keys with the same placeholders, or a
+// client silently gets a half-translated message.
+func TestCatalogsMatch(t *testing.T) {
+	en := catalogs[English]
+	for lang, msgs := range catalogs {
+		for key := range en {
+			msg, ok := msgs[key]
+			if !ok {
+				t.Errorf("%s catalog is missing %q", lang, key)
+				continue
+			}
+			if got, want := placeholders(msg), placeholders(en[key]); got != want {
+				t.Errorf("%s %q has placeholders %s, English has %s", lang, key, got, want)
+			}
+		}
+		for key := range msgs {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s catalog has %q, which English lacks", lang, key)
+			}
+		}
+	}
+}
+
+func placeholders(msg string) string {
+	found := placeholder.FindAllString(msg, -1)
+	sort.Strings(found)
+	return strings.Join(found, ",")
+}
+
+var localizedKey = regexp.MustCompile(`(?:Localized\(|apperror\.Field\([^,]+, )"([a-z_.]+)"`)
+
+// every key the services use must be in the catalogs.
+func TestSourceKeysExist(t *testing.T) {
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range localizedKey.FindAllStringSubmatch(string(src), -1) {
+			key := m[1]
+			if strings.HasPrefix(m[0], "apperror.Field") {
+				key = "field." + key
+			}
+			if _, ok := catalogs[English][key]; !ok {
+				t.Errorf("%s uses %q, which is not in the catalogs", path, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,282 @@
+package redact
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Mode is how a sensitive attribute is rewritten.
+type Mode int
+
+const (
+	// ModeMask keeps the last four characters, like CardToken.
+	ModeMask Mode = iota
+	// ModeHash replaces the value with a short SHA-256 digest, so log lines
+	// about the same person can still be correlated.
+	ModeHash
+	// ModeDrop replaces the value entirely.
+	ModeDrop
+)
+
+// DefaultKeys are the attribute keys redacted unless allowed. Keys are
+// compared ignoring case, underscores and dashes, so a struct field named
+// CardToken matches card_token.
+var DefaultKeys = map[string]Mode{
+	"card_token":   ModeMask,
+	"card_number":  ModeDrop,
+	"pan":          ModeDrop,
+	"cvv":          ModeDrop,
+	"email":        ModeHash,
+	"phone":        ModeHash,
+	"phone_number": ModeHash,
+	"mobile":       ModeHash,
+	"user_id":      ModeHash,
+}
+
+const dropped = "[REDACTED]"
+
+// pattern finds sensitive values inside free text such as messages and
+// error strings, whatever key they are logged under.
+type pattern struct {
+	re   *regexp.Regexp
+	mode Mode
+}
+
+var defaultPatterns = []pattern{
+	{re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), mode: ModeHash},
+	// international numbers and Saudi mobile numbers written locally
+	{re: regexp.MustCompile(`\+\d{8,15}\b|\b05\d{8}\b`), mode: ModeHash},
+}
+
+// panCandidate finds digit runs shaped like a card number: a network prefix
+// (2-6) and 13 to 19 digits, bare or in groups of four. Candidates are only
+// dropped when they stand alone and pass the Luhn check; see scrubPANs.
+var panCandidate = regexp.MustCompile(`[2-6]\d{12,18}|[2-6]\d{3}(?:[ -]\d{4}){3}(?:[ -]\d{1,3})?`)
+
+// Options configures a Handler.
+type Options struct {
+	// Keys overrides DefaultKeys when non-nil.
+	Keys map[string]Mode
+	// Allow lists keys that are logged as is, for example user_id in
+	// development. Allowed keys are also exempt from pattern matching.
+	Allow []string
+}
+
+// Handler rewrites sensitive attributes before passing records on to the
+// wrapped handler. Attributes are matched by key; string values, errors and
+// the message are also scanned for sensitive patterns. Structs, maps and
+// slices are walked field by field under the same rules.
+type Handler struct {
+	next  slog.Handler
+	keys  map[string]Mode
+	allow map[string]bool
+}
+
+func NewHandler(next slog.Handler, opts Options) *Handler {
+	keys := opts.Keys
+	if keys == nil {
+		keys = DefaultKeys
+	}
+
+	h := &Handler{
+		next:  next,
+		keys:  make(map[string]Mode, len(keys)),
+		allow: make(map[string]bool, len(opts.Allow)),
+	}
+	for k, m := range keys {
+		h.keys[normalizeKey(k)] = m
+	}
+	for _, k := range opts.Allow {
+		h.allow[normalizeKey(strings.TrimSpace(k))] = true
+	}
+	return h
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, scrub(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), keys: h.keys, allow: h.allow}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), keys: h.keys, allow: h.allow}
+}
+
+func (h *Handler) attr(a slog.Attr) slog.Attr {
+	key := normalizeKey(a.Key)
+	if h.allow[key] {
+		return a
+	}
+
+	v := a.Value.Resolve()
+
+	if v.Kind() == slog.KindGroup {
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = h.attr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	}
+
+	if mode, ok := h.keys[key]; ok {
+		return slog.String(a.Key, apply(mode, v.String()))
+	}
+
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, scrub(v.String()))
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, scrub(err.Error()))
+		}
+		return slog.Any(a.Key, h.any(v.Any()))
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// any redacts an arbitrary value (a struct, map or slice) by walking its
+// JSON form, so nested fields get the same key and pattern rules as
+// attributes. A value that cannot be encoded is not logged at all.
+func (h *Handler) any(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return dropped
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return dropped
+	}
+	return h.walk(decoded)
+}
+
+func (h *Handler) walk(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			key := normalizeKey(k)
+			switch mode, ok := h.keys[key]; {
+			case h.allow[key]:
+			case ok && val != nil:
+				v[k] = apply(mode, fmt.Sprint(val))
+			default:
+				v[k] = h.walk(val)
+			}
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = h.walk(v[i])
+		}
+		return v
+	case string:
+		return scrub(v)
+	default:
+		return v
+	}
+}
+
+func normalizeKey(k string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(k))
+}
+
+// scrub rewrites every sensitive pattern found in s.
+func scrub(s string) string {
+	for _, p := range defaultPatterns {
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string { return apply(p.mode, m) })
+	}
+	return scrubPANs(s)
+}
+
+// scrubPANs drops card numbers from s. A candidate that is part of a longer
+// identifier, such as "txn-1760860000000" or "ord_4111111111111111x", is kept
+// so correlation IDs and timestamps survive.
+func scrubPANs(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range panCandidate.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && isIdentByte(s[start-1]) || end < len(s) && isIdentByte(s[end]) {
+			continue
+		}
+		if !luhn(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(dropped)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isIdentByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-'
+}
+
+// luhn reports whether the digits of s (separators ignored) carry a valid
+// Luhn check digit, as every card number does.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func apply(mode Mode, v string) string {
+	switch mode {
+	case ModeMask:
+		return CardToken(v)
+	case ModeHash:
+		return Hash(v)
+	default:
+		return dropped
+	}
+}
+
+// Hash returns a short, stable digest of v for correlating log lines without
+// logging the value itself.
+func Hash(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
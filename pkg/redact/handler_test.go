@@ -0,0 +1,189 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(opts Options) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), opts)), &buf
+}
+
+// secrets must never appear in log output unless their key is allowed.
+var secrets = []string{
+	"tok_visa_4242424242",
+	"sara@example.com",
+	"+966501234567",
+	"0501234567",
+	"4111111111111111",
+	"3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11",
+}
+
+func assertNoLeak(t *testing.T, out string) {
+	t.Helper()
+	for _, s := range secrets {
+		if strings.Contains(out, s) {
+			t.Errorf("log output leaks %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestHandler_RedactsSensitiveKeys(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	logger.Info("[FAKE PSP] Charge",
+		"card_token", "tok_visa_4242424242",
+		"Email", "sara@example.com",
+		"phone", "+966501234567",
+		"user_id", "3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11",
+		"card_number", 4111111111111111,
+		"amount", 150000,
+	)
+
+	out := buf.String()
+	assertNoLeak(t, out)
+	if !strings.Contains(out, `"card_token":"****4242"`) {
+		t.Errorf("expected masked card token, got %s", out)
+	}
+	if !strings.Contains(out, `"user_id":"`+Hash("3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11")+`"`) {
+		t.Errorf("expected hashed user id, got %s", out)
+	}
+	if !strings.Contains(out, `"amount":150000`) {
+		t.Errorf("expected amount to be kept, got %s", out)
+	}
+}
+
+func TestHandler_ScrubsPatternsInText(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	logger.Error("notifying sara@example.com failed",
+		"error", errors.New("sms to 0501234567 bounced"),
+		"detail", "card 4111111111111111 rejected",
+	)
+
+	assertNoLeak(t, buf.String())
+}
+
+func TestHandler_PANsNeedCardShape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		kept bool
+	}{
+		{name: "bare card number", in: "card 4111111111111111 rejected"},
+		{name: "grouped card number", in: "card 4111 1111 1111 1111 rejected"},
+		{name: "dashed card number", in: "card 5555-5555-5555-4444 rejected"},
+		{name: "transaction id", in: "fake-txn-1760860000000", kept: true},
+		{name: "millisecond timestamp", in: "at 1760860000000", kept: true},
+		{name: "fails luhn", in: "loan 4111111111111112", kept: true},
+		{name: "inside an identifier", in: "ref_4111111111111111", kept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scrub(tt.in)
+			if kept := got == tt.in; kept != tt.kept {
+				t.Errorf("scrub(%q) = %q", tt.in, got)
+			}
+		})
+	}
+}
+
+type chargeLog struct {
+	OrderID   string
+	CardToken string
+	Customer  struct {
+		Email string `json:"email"`
+	}
+	Items []map[string]any
+}
+
+func TestHandler_RedactsStructValues(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	v := chargeLog{OrderID: "ord-1", CardToken: "tok_visa_4242424242"}
+	v.Customer.Email = "sara@example.com"
+	v.Items = []map[string]any{{"note": "paid with 4111111111111111", "qty": 2}}
+	logger.Info("charge", "req", v, "ptr", &v)
+
+	out := buf.String()
+	assertNoLeak(t, out)
+	if !strings.Contains(out, `"CardToken":"****4242"`) || !strings.Contains(out, `"OrderID":"ord-1"`) {
+		t.Errorf("expected struct fields to be redacted by key, got %s", out)
+	}
+	if !strings.Contains(out, `"qty":2`) {
+		t.Errorf("expected numbers to be kept, got %s", out)
+	}
+}
+
+func TestHandler_DropsUnencodableValues(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	logger.Info("odd", "fn", func() {})
+
+	if !strings.Contains(buf.String(), `"fn":"[REDACTED]"`) {
+		t.Errorf("expected unencodable value to be dropped, got %s", buf.String())
+	}
+}
+
+func TestHandler_GroupsAndWithAttrs(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	logger.
+		With("user_id", "3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11").
+		WithGroup("req").
+		Info("request",
+			slog.Group("customer", "email", "sara@example.com", "phone", "+966501234567"),
+			"card_token", slog.StringValue("tok_visa_4242424242"),
+		)
+
+	assertNoLeak(t, buf.String())
+}
+
+type tokenValuer string
+
+func (v tokenValuer) LogValue() slog.Value { return slog.StringValue(string(v)) }
+
+func TestHandler_ResolvesLogValuers(t *testing.T) {
+	logger, buf := newTestLogger(Options{})
+
+	logger.Info("charge", "card_token", tokenValuer("tok_visa_4242424242"))
+
+	assertNoLeak(t, buf.String())
+}
+
+func TestHandler_Allowlist(t *testing.T) {
+	logger, buf := newTestLogger(Options{Allow: []string{"user_id"}})
+
+	logger.Info("request",
+		"user_id", "3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11",
+		"card_token", "tok_visa_4242424242",
+	)
+
+	out := buf.String()
+	if !strings.Contains(out, "3f6c2a54-4c58-4b8e-9d0e-6f1b8c2f0a11") {
+		t.Errorf("expected allowed user_id to be logged as is, got %s", out)
+	}
+	if strings.Contains(out, "tok_visa_4242424242") {
+		t.Errorf("expected card token to stay redacted, got %s", out)
+	}
+}
+
+func TestCardToken(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "tok_visa_4242", want: "****4242"},
+		{in: "abcd", want: "****"},
+		{in: "", want: "****"},
+	}
+
+	for _, tt := range tests {
+		if got := CardToken(tt.in); got != tt.want {
+			t.Errorf("CardToken(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
+)
+
+// ProblemContentType is the media type of an RFC 7807 problem document.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem document, the opt-in alternative to
+// the error envelope. Code, DeclineCode, RequestID and Errors are extension
+// members carrying what ErrorBody carries.
+type ProblemDetails struct {
+	Type        string                `json:"type"`
+	Title       string                `json:"title"`
+	Status      int                   `json:"status"`
+	Detail      string                `json:"detail,omitempty"`
+	Instance    string                `json:"instance,omitempty"`
+	Code        string                `json:"code"`
+	DeclineCode string                `json:"decline_code,omitempty"`
+	RequestID   string                `json:"request_id,omitempty"`
+	Errors      []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// ProblemType is the type URI of the problem with the given error code.
+func ProblemType(code string) string {
+	return "urn:ppo:problem:" + strings.ToLower(code)
+}
+
+// Problem writes p as application/problem+json.
+func Problem(c *gin.Context, p ProblemDetails) {
+	// the JSON renderer keeps a content type that is already set
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(p.Status, p)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/example/ppo/pkg/apperror"
 )
 
 type APIResponse struct {
@@ -13,8 +15,11 @@ type APIResponse struct {
 }
 
 type ErrorBody struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	DeclineCode string `json:"decline_code,omitempty"`
+	// Details names the fields that failed validation.
+	Details []apperror.FieldError `json:"details,omitempty"`
 }
 
 func OK(c *gin.Context, data interface{}) {
@@ -32,11 +37,14 @@ func Created(c *gin.Context, data interface{}) {
 }
 
 func Err(c *gin.Context, status int, code, message string) {
+	ErrBody(c, status, ErrorBody{Code: code, Message: message})
+}
+
+// ErrBody writes an error response whose body carries more than a code and
+// a message.
+func ErrBody(c *gin.Context, status int, body ErrorBody) {
 	c.JSON(status, APIResponse{
 		Success: false,
-		Error: &ErrorBody{
-			Code:    code,
-			Message: message,
-		},
+		Error:   &body,
 	})
 }